@@ -3,6 +3,7 @@ package assets
 import (
 	"embed"
 	"image"
+	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
 
@@ -17,15 +18,14 @@ var assets embed.FS
 //go:embed font/PublicPixel.ttf
 var Font []byte
 
+//go:embed physics/default.json
+var PhysicsProfileJSON []byte
+
 var FontFaceS text.Face
 var FontFaceM text.Face
 
 var (
 	CharacterSpritesheet = esset.GetAsset(assets, "images/sprites/adventurer-Sheet.png")
-
-	DesertBackground1 = esset.GetAsset(assets, "images/backgrounds/desert/background1.png")
-	DesertBackground2 = esset.GetAsset(assets, "images/backgrounds/desert/background2.png")
-	DesertBackground3 = esset.GetAsset(assets, "images/backgrounds/desert/background3.png")
 )
 
 func InitCharacterAnimations() *SimpleAnimationManager {
@@ -38,6 +38,9 @@ func InitCharacterAnimations() *SimpleAnimationManager {
 	animManager.AddAnimation("walk", 155, 160, 0.18, true)
 	animManager.AddAnimation("roll", 24, 27, 0.06, false)
 	animManager.AddAnimation("slip", 24, 25, 0.08, false)
+	animManager.AddAnimation("dash", 25, 26, 0.05, true)
+	animManager.AddAnimation("ledge-grab", 29, 32, 0.12, true)
+	animManager.AddAnimation("ledge-climb", 33, 37, 0.06, false)
 
 	animManager.AddAnimation("attack1", 42, 46, 0.05, false)
 	animManager.AddAnimation("attack2", 47, 52, 0.05, false)
@@ -150,6 +153,16 @@ func (sam *SimpleAnimationManager) DrawWithOptions(screen *ebiten.Image, op *ebi
 	screen.DrawImage(sprite, op)
 }
 
+// CurrentFrame returns the sprite of the frame being shown, or nil when no
+// animation is playing.
+func (sam *SimpleAnimationManager) CurrentFrame() *ebiten.Image {
+	anim, exists := sam.animations[sam.currentAnim]
+	if !exists || len(anim.frames) == 0 {
+		return nil
+	}
+	return sam.spritesheet.SubImage(anim.frames[sam.currentFrame]).(*ebiten.Image)
+}
+
 type SpriteAnimation struct {
 	Spritesheet  *ebiten.Image
 	Frames       []image.Rectangle
@@ -275,6 +288,8 @@ func (sam *SimpleAnimationManager) IsAnimationFinished() bool {
 	return !anim.loop && sam.currentFrame >= len(anim.frames)-1
 }
 
+// BackgroundLayer is a parallax image drawn behind the tiles. Maps provide
+// them as image layers, see loadBackgroundLayers.
 type BackgroundLayer struct {
 	Image     *ebiten.Image
 	Name      string
@@ -287,21 +302,31 @@ type BackgroundLayer struct {
 	RepeatY   bool
 	ScaleX    float64
 	ScaleY    float64
+	Opacity   float64
+	Tint      color.Color
 }
 
-func DesertLayers() []BackgroundLayer {
-	return []BackgroundLayer{
-		{DesertBackground1, "desert_bg1", 0.1, 0.05, -6, 0, 0, true, false, 1.5, 1.5},
-		{DesertBackground2, "desert_bg2", 0.25, 0.1, -4, 0, 0, true, false, 1.5, 1.5},
-		{DesertBackground3, "desert_bg3", 0.4, 0.15, -2, 0, 0, true, false, 1.5, 1.5},
-	}
+const DefaultEnvironment = "dust_of_divided_sun"
+
+// environmentMaps names the map whose backgrounds stand for an environment
+// outside of a level, e.g. behind the menu.
+var environmentMaps = map[string]string{
+	DefaultEnvironment: DesertMapPath,
 }
 
-func GetLayersByEnvironment() []BackgroundLayer {
-	return DesertLayers()
+func GetLayersByEnvironment(environment string) []BackgroundLayer {
+	mapPath, ok := environmentMaps[environment]
+	if !ok {
+		mapPath = environmentMaps[DefaultEnvironment]
+	}
+	if tileMap := GetTileMap(mapPath); tileMap != nil {
+		return tileMap.Backgrounds
+	}
+	return nil
 }
 
 func DrawBackgroundLayers(screen *ebiten.Image, layers []BackgroundLayer, cameraX, cameraY float64, screenWidth int) {
+	screenHeight := screen.Bounds().Dy()
 	for _, layer := range layers {
 		if layer.Image == nil {
 			continue
@@ -314,28 +339,36 @@ func DrawBackgroundLayers(screen *ebiten.Image, layers []BackgroundLayer, camera
 		finalY := layer.OffsetY - parallaxOffsetY
 
 		opts := &ebiten.DrawImageOptions{}
+		if layer.Tint != nil {
+			opts.ColorScale.ScaleWithColor(layer.Tint)
+		}
+		opts.ColorScale.ScaleAlpha(float32(layer.Opacity))
 
-		if layer.RepeatX {
-			imgWidth := float64(layer.Image.Bounds().Dx())
-			startX := finalX
-
-			effectiveScreenWidth := float64(screenWidth)
-
-			for startX > 0 {
-				startX -= imgWidth
-			}
-
-			for x := startX; x < effectiveScreenWidth; x += imgWidth {
+		xs := backgroundTilePositions(finalX, float64(layer.Image.Bounds().Dx()), float64(screenWidth), layer.RepeatX)
+		ys := backgroundTilePositions(finalY, float64(layer.Image.Bounds().Dy()), float64(screenHeight), layer.RepeatY)
+		for _, y := range ys {
+			for _, x := range xs {
 				opts.GeoM.Reset()
-				opts.GeoM.Translate(x, finalY)
+				opts.GeoM.Translate(x, y)
 				opts.GeoM.Scale(layer.ScaleX, layer.ScaleY)
 				screen.DrawImage(layer.Image, opts)
 			}
-		} else {
-			opts.GeoM.Reset()
-			opts.GeoM.Translate(finalX, finalY)
-			opts.GeoM.Scale(layer.ScaleX, layer.ScaleY)
-			screen.DrawImage(layer.Image, opts)
 		}
 	}
 }
+
+// backgroundTilePositions returns where copies of an image go along one axis
+// so that a repeating layer covers the whole screen.
+func backgroundTilePositions(start, size, screenSize float64, repeat bool) []float64 {
+	if !repeat || size <= 0 {
+		return []float64{start}
+	}
+	for start > 0 {
+		start -= size
+	}
+	var positions []float64
+	for p := start; p < screenSize; p += size {
+		positions = append(positions, p)
+	}
+	return positions
+}
@@ -0,0 +1,51 @@
+package assets
+
+import (
+	"path/filepath"
+
+	"github.com/lafriks/go-tiled"
+	"github.com/temidaradev/esset/v2"
+)
+
+// loadBackgroundLayers builds the parallax backgrounds from the map's image
+// layers, back to front. Besides Tiled's own layer attributes it reads two
+// custom properties:
+//
+//	scale float  draw scale of the image (default 1)
+//	tint  color  multiplied into the image; go-tiled does not expose tintcolor
+func loadBackgroundLayers(gameMap *tiled.Map, mapPath string, parallax layerParallax) []BackgroundLayer {
+	var layers []BackgroundLayer
+	for _, imageLayer := range gameMap.ImageLayers {
+		if !imageLayer.Visible || imageLayer.Image == nil || imageLayer.Image.Source == "" {
+			continue
+		}
+
+		scale := 1.0
+		if len(imageLayer.Properties.Get("scale")) > 0 {
+			scale = imageLayer.Properties.GetFloat("scale")
+		}
+
+		parallaxX, parallaxY := parallax.factors(imageLayer.ID)
+		imagePath := filepath.Join(filepath.Dir(mapPath), imageLayer.Image.Source)
+		layers = append(layers, BackgroundLayer{
+			Image:     esset.GetAsset(assets, imagePath),
+			Name:      imageLayer.Name,
+			ParallaxX: parallaxX,
+			ParallaxY: parallaxY,
+			OffsetX:   float64(imageLayer.OffsetX + imageLayer.X),
+			OffsetY:   float64(imageLayer.OffsetY + imageLayer.Y),
+			RepeatX:   imageLayer.RepeatX,
+			RepeatY:   imageLayer.RepeatY,
+			ScaleX:    scale,
+			ScaleY:    scale,
+			Opacity:   float64(imageLayer.Opacity),
+			Tint:      imageLayer.Properties.GetColor("tint"),
+		})
+	}
+
+	// Layers further back sit deeper, two apart like the old hand-made lists.
+	for i := range layers {
+		layers[i].ZDepth = (i - len(layers)) * 2
+	}
+	return layers
+}
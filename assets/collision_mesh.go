@@ -0,0 +1,263 @@
+package assets
+
+import (
+	"image/color"
+	"log"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+)
+
+type collisionTileKey struct {
+	layer, x, y int
+}
+
+// tileRect is a rectangle of tiles in tile coordinates. Group is the tile
+// group while meshing and the layer once the rectangle has a shape.
+type tileRect struct {
+	X, Y          int
+	Width, Height int
+	Group         int
+}
+
+// createCollisionObjects merges each layer's tiles with identical collision
+// behaviour into as few rectangles as possible and remembers which shape
+// covers which tile.
+func (tm *TileMap) createCollisionObjects() {
+	if tm.Map == nil || tm.CollisionSpace == nil {
+		return
+	}
+
+	tm.collisionTiles = make(map[collisionTileKey][]*resolv.ConvexPolygon)
+	tm.shapeRects = make(map[*resolv.ConvexPolygon]tileRect)
+	shapeTiles := 0
+	for layerIndex := range tm.Map.Layers {
+		shapeTiles += tm.buildCollisionRegion(layerIndex, tileRect{Width: tm.MapWidth, Height: tm.MapHeight})
+	}
+	log.Printf("Created collision objects for tilemap: %d shapes from %d tiles", len(tm.CollisionSpace.Shapes()), shapeTiles)
+}
+
+// buildCollisionRegion creates the collision shapes for the tiles of one
+// layer inside region and returns how many tiles needed a shape.
+func (tm *TileMap) buildCollisionRegion(layerIndex int, region tileRect) int {
+	layer := tm.Map.Layers[layerIndex]
+	// Foreground layers are scenery the player passes behind.
+	if len(layer.Tiles) == 0 || !layer.Visible || isForegroundLayer(layer) {
+		return 0
+	}
+
+	// Tiles are grouped by behaviour; group 0 means no shape.
+	groups := make([]int, region.Width*region.Height)
+	groupInfo := []TileCollisionInfo{{}}
+	groupOf := make(map[TileCollisionInfo]int)
+	shapeTiles := 0
+	for i := range groups {
+		x, y := region.X+i%region.Width, region.Y+i/region.Width
+		tileIndex := y*tm.MapWidth + x
+		if tileIndex >= len(layer.Tiles) {
+			continue
+		}
+		info := tm.layerTileInfo(layer.Tiles[tileIndex])
+		if !info.HasShape() {
+			continue
+		}
+		info.ID = 0
+		shapeTiles++
+
+		// Slopes and other outlines get a shape per tile and stay out of
+		// the rectangle merging.
+		if outlines := tm.layerTileOutlines(layer.Tiles[tileIndex], info); len(outlines) > 0 {
+			for _, outline := range outlines {
+				shape := tm.outlineShape(x, y, outline)
+				tm.addCollisionShape(layerIndex, shape, tileRect{X: x, Y: y, Width: 1, Height: 1}, info, info.tags()|TagSlope)
+			}
+			continue
+		}
+
+		group, ok := groupOf[info]
+		if !ok {
+			group = len(groupInfo)
+			groupOf[info] = group
+			groupInfo = append(groupInfo, info)
+		}
+		groups[i] = group
+	}
+
+	for _, rect := range greedyMesh(groups, region.Width, region.Height) {
+		rect.X += region.X
+		rect.Y += region.Y
+		info := groupInfo[rect.Group]
+		tm.addCollisionShape(layerIndex, tm.tileRectShape(rect), rect, info, info.tags())
+	}
+	return shapeTiles
+}
+
+func (tm *TileMap) addCollisionShape(layerIndex int, shape *resolv.ConvexPolygon, rect tileRect, info TileCollisionInfo, tags resolv.Tags) {
+	shape.SetData(info)
+	shape.Tags().Set(tags)
+	tm.CollisionSpace.Add(shape)
+
+	rect.Group = layerIndex
+	tm.shapeRects[shape] = rect
+	for y := rect.Y; y < rect.Y+rect.Height; y++ {
+		for x := rect.X; x < rect.X+rect.Width; x++ {
+			key := collisionTileKey{layerIndex, x, y}
+			tm.collisionTiles[key] = append(tm.collisionTiles[key], shape)
+		}
+	}
+}
+
+// removeCollisionShapes takes the shapes covering a tile out of the space and
+// returns the block of tiles they covered, which is left without collision.
+func (tm *TileMap) removeCollisionShapes(layerIndex, tileX, tileY int) tileRect {
+	region := tileRect{X: tileX, Y: tileY, Width: 1, Height: 1}
+	for _, shape := range tm.collisionTiles[collisionTileKey{layerIndex, tileX, tileY}] {
+		rect := tm.shapeRects[shape]
+		region = region.union(rect)
+		tm.CollisionSpace.Remove(shape)
+		delete(tm.shapeRects, shape)
+		for y := rect.Y; y < rect.Y+rect.Height; y++ {
+			for x := rect.X; x < rect.X+rect.Width; x++ {
+				key := collisionTileKey{layerIndex, x, y}
+				tm.collisionTiles[key] = slices.DeleteFunc(tm.collisionTiles[key], func(other *resolv.ConvexPolygon) bool {
+					return other == shape
+				})
+				if len(tm.collisionTiles[key]) == 0 {
+					delete(tm.collisionTiles, key)
+				}
+			}
+		}
+	}
+	return region
+}
+
+func (r tileRect) union(other tileRect) tileRect {
+	minX, minY := min(r.X, other.X), min(r.Y, other.Y)
+	maxX, maxY := max(r.X+r.Width, other.X+other.Width), max(r.Y+r.Height, other.Y+other.Height)
+	return tileRect{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY, Group: r.Group}
+}
+
+// greedyMesh covers every non-zero cell of a row-major grid with maximal
+// rectangles of a single group, growing each one right first and then down.
+func greedyMesh(groups []int, width, height int) []tileRect {
+	used := make([]bool, len(groups))
+	free := func(x, y, group int) bool {
+		i := y*width + x
+		return groups[i] == group && !used[i]
+	}
+
+	var rects []tileRect
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			group := groups[y*width+x]
+			if group == 0 || used[y*width+x] {
+				continue
+			}
+
+			w := 1
+			for x+w < width && free(x+w, y, group) {
+				w++
+			}
+
+			h := 1
+		grow:
+			for y+h < height {
+				for dx := 0; dx < w; dx++ {
+					if !free(x+dx, y+h, group) {
+						break grow
+					}
+				}
+				h++
+			}
+
+			for dy := 0; dy < h; dy++ {
+				for dx := 0; dx < w; dx++ {
+					used[(y+dy)*width+x+dx] = true
+				}
+			}
+			rects = append(rects, tileRect{X: x, Y: y, Width: w, Height: h, Group: group})
+		}
+	}
+	return rects
+}
+
+// tileRectShape builds the collision shape for a block of tiles. Tile shapes
+// have always been centred on the tile's top-left corner rather than covering
+// the drawn tile, and gameplay is tuned around that, so merged blocks keep the
+// same half-tile offset.
+func (tm *TileMap) tileRectShape(rect tileRect) *resolv.ConvexPolygon {
+	left, top := tm.tileShapeOrigin(rect.X, rect.Y)
+	return resolv.NewRectangleFromTopLeft(left, top,
+		float64(rect.Width*tm.TileWidth),
+		float64(rect.Height*tm.TileHeight))
+}
+
+// tileShapeOrigin is the top-left corner of a tile's collision area.
+func (tm *TileMap) tileShapeOrigin(tileX, tileY int) (x, y float64) {
+	return float64(tileX*tm.TileWidth) - float64(tm.TileWidth)/2,
+		float64(tileY*tm.TileHeight) - float64(tm.TileHeight)/2
+}
+
+// shapeSurfaceY is the landing reference of a shape: the centre of its top
+// row of tiles, which is what a single tile shape's position used to be.
+func (tm *TileMap) shapeSurfaceY(shape *resolv.ConvexPolygon) float64 {
+	return shape.Bounds().Min.Y + float64(tm.TileHeight)/2
+}
+
+// CollisionShapeAt returns the merged shape covering a tile, or nil when the
+// tile has no collision.
+func (tm *TileMap) CollisionShapeAt(layer, tileX, tileY int) *resolv.ConvexPolygon {
+	if shapes := tm.collisionTiles[collisionTileKey{layer, tileX, tileY}]; len(shapes) > 0 {
+		return shapes[0]
+	}
+	return nil
+}
+
+// DrawCollisionDebug outlines the merged collision shapes and, faintly, the
+// tiles each one was built from.
+func (tm *TileMap) DrawCollisionDebug(screen *ebiten.Image, cameraX, cameraY float64) {
+	if tm.Map == nil || tm.CollisionSpace == nil {
+		return
+	}
+
+	view := screen.Bounds()
+	startX := int(cameraX)/tm.TileWidth - 1
+	endX := int(cameraX+float64(view.Dx()))/tm.TileWidth + 1
+	startY := int(cameraY)/tm.TileHeight - 1
+	endY := int(cameraY+float64(view.Dy()))/tm.TileHeight + 1
+
+	tileColor := color.RGBA{0, 160, 255, 60}
+	for layer := range tm.Map.Layers {
+		for y := max(startY, 0); y <= min(endY, tm.MapHeight-1); y++ {
+			for x := max(startX, 0); x <= min(endX, tm.MapWidth-1); x++ {
+				if tm.CollisionShapeAt(layer, x, y) == nil {
+					continue
+				}
+				left, top := tm.tileShapeOrigin(x, y)
+				vector.StrokeRect(screen, float32(left-cameraX), float32(top-cameraY),
+					float32(tm.TileWidth), float32(tm.TileHeight), 1, tileColor, false)
+			}
+		}
+	}
+
+	shapeColor := color.RGBA{0, 200, 255, 200}
+	for _, shape := range tm.CollisionSpace.Shapes() {
+		bounds := shape.Bounds()
+		if bounds.Max.X < cameraX || bounds.Min.X > cameraX+float64(view.Dx()) {
+			continue
+		}
+		if polygon, ok := shape.(*resolv.ConvexPolygon); ok && shape.Tags().Has(TagSlope) {
+			points := polygon.Transformed()
+			for i, from := range points {
+				to := points[(i+1)%len(points)]
+				vector.StrokeLine(screen, float32(from.X-cameraX), float32(from.Y-cameraY),
+					float32(to.X-cameraX), float32(to.Y-cameraY), 2, shapeColor, false)
+			}
+			continue
+		}
+		vector.StrokeRect(screen, float32(bounds.Min.X-cameraX), float32(bounds.Min.Y-cameraY),
+			float32(bounds.Width()), float32(bounds.Height()), 2, shapeColor, false)
+	}
+}
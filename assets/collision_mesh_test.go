@@ -0,0 +1,113 @@
+package assets
+
+import "testing"
+
+// parseGroups reads a grid of digits into greedyMesh's row-major groups.
+func parseGroups(rows ...string) []int {
+	groups := make([]int, 0, len(rows)*len(rows[0]))
+	for _, row := range rows {
+		for _, cell := range row {
+			groups = append(groups, int(cell-'0'))
+		}
+	}
+	return groups
+}
+
+func TestGreedyMesh(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []string
+		rects int
+	}{
+		{"empty", []string{"000", "000"}, 0},
+		{"single tile", []string{"010", "000"}, 1},
+		{"row", []string{"1111", "0000"}, 1},
+		{"block", []string{"111", "111", "111"}, 1},
+		{"two groups side by side", []string{"1122", "1122"}, 2},
+		{"gap splits a row", []string{"11011"}, 2},
+		{"L shape", []string{"100", "100", "111"}, 2},
+		{"step", []string{"0011", "1111"}, 2},
+		{"checkerboard", []string{"101", "010", "101"}, 5},
+		{"floor under platform", []string{"0220", "0000", "1111", "1111"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := len(tt.rows[0]), len(tt.rows)
+			groups := parseGroups(tt.rows...)
+			rects := greedyMesh(groups, width, height)
+			if len(rects) != tt.rects {
+				t.Errorf("got %d rects, want %d: %v", len(rects), tt.rects, rects)
+			}
+
+			covered := make([]int, len(groups))
+			for _, rect := range rects {
+				for y := rect.Y; y < rect.Y+rect.Height; y++ {
+					for x := rect.X; x < rect.X+rect.Width; x++ {
+						i := y*width + x
+						covered[i]++
+						if groups[i] != rect.Group {
+							t.Errorf("rect %v covers tile %d,%d of group %d", rect, x, y, groups[i])
+						}
+					}
+				}
+			}
+			for i, count := range covered {
+				if want := min(groups[i], 1); count != want {
+					t.Errorf("tile %d,%d covered %d times, want %d", i%width, i/width, count, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCollisionShapeCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		shapes int
+	}{
+		{"empty map", []string{"....", "...."}, 0},
+		{"floor", []string{"....", "####", "####"}, 1},
+		{"platform over floor", []string{".==.", "....", "####"}, 2},
+		{"platform touching floor", []string{"====", "####"}, 2},
+		{"pit", []string{"......", "##..##", "##..##"}, 2},
+		// The pillar column reaches down through the floor, cutting it in two.
+		{"pillar on floor", []string{".#..", ".#..", "####"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := newTestTileMap(tt.rows...)
+			if got := len(tm.CollisionSpace.Shapes()); got != tt.shapes {
+				t.Errorf("got %d shapes, want %d", got, tt.shapes)
+			}
+		})
+	}
+}
+
+func TestClearTileRemeshes(t *testing.T) {
+	tm := newTestTileMap("......", "######", "######")
+	if got := len(tm.CollisionSpace.Shapes()); got != 1 {
+		t.Fatalf("got %d shapes before the edit, want 1", got)
+	}
+
+	if err := tm.ClearTile(2, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if tm.CollisionShapeAt(0, 2, 1) != nil {
+		t.Error("cleared tile still has a shape")
+	}
+	// The notch splits the floor into the blocks left and right of it and
+	// the tile under it.
+	if got := len(tm.CollisionSpace.Shapes()); got != 3 {
+		t.Errorf("got %d shapes after the edit, want 3", got)
+	}
+
+	if err := tm.SetTile(2, 1, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if tm.CollisionShapeAt(0, 2, 1) == nil {
+		t.Error("restored tile has no shape")
+	}
+}
@@ -0,0 +1,131 @@
+package assets
+
+import (
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lafriks/go-tiled"
+)
+
+const (
+	// ForegroundLayerPrefix marks a tile layer as foreground by name, as an
+	// alternative to the foreground property.
+	ForegroundLayerPrefix = "foreground"
+
+	foregroundFadeAlpha = 0.3
+	foregroundFadeSpeed = 4.0
+)
+
+// foregroundLayer is a tile layer drawn over the player. Layers are marked
+// by name or with custom properties:
+//
+//	foreground bool  draw above the player instead of behind
+//	fade       bool  turn see-through while the player is behind it
+//
+// Tiled's parallaxx/parallaxy are honoured.
+type foregroundLayer struct {
+	layer     *tiled.Layer
+	parallaxX float64
+	parallaxY float64
+	fade      bool
+	alpha     float64
+}
+
+func isForegroundLayer(layer *tiled.Layer) bool {
+	return layer.Properties.GetBool("foreground") ||
+		strings.HasPrefix(strings.ToLower(layer.Name), ForegroundLayerPrefix)
+}
+
+func parseForegroundLayers(gameMap *tiled.Map, parallax layerParallax) []*foregroundLayer {
+	var layers []*foregroundLayer
+	for _, layer := range gameMap.Layers {
+		if len(layer.Tiles) == 0 || !layer.Visible || !isForegroundLayer(layer) {
+			continue
+		}
+		parallaxX, parallaxY := parallax.factors(layer.ID)
+		layers = append(layers, &foregroundLayer{
+			layer:     layer,
+			parallaxX: parallaxX,
+			parallaxY: parallaxY,
+			fade:      layer.Properties.GetBool("fade"),
+			alpha:     float64(layer.Opacity),
+		})
+	}
+	return layers
+}
+
+// renderPassLayers returns the tile layers baked into the chunks of a render
+// pass: pass 0 holds every layer behind the player, pass n the n-th
+// foreground layer.
+func (tm *TileMap) renderPassLayers(pass int) []*tiled.Layer {
+	if pass > 0 {
+		return []*tiled.Layer{tm.foreground[pass-1].layer}
+	}
+	var layers []*tiled.Layer
+	for _, layer := range tm.Map.Layers {
+		if len(layer.Tiles) > 0 && layer.Visible && !isForegroundLayer(layer) {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// SetFocus tells the map where the player is and where the camera looks,
+// for fading foreground layers.
+func (tm *TileMap) SetFocus(x, y, width, height, cameraX, cameraY float64) {
+	tm.focusX, tm.focusY, tm.focusW, tm.focusH = x, y, width, height
+	tm.focusCameraX, tm.focusCameraY = cameraX, cameraY
+}
+
+// layerFocus returns where the focus box sits in a layer's own tile space.
+// The player is drawn at x-cameraX and the layer's tiles at
+// tileX-cameraX*parallax, so the two line up at x-cameraX*(1-parallax).
+func (tm *TileMap) layerFocus(fg *foregroundLayer) (x, y float64) {
+	return tm.focusX - tm.focusCameraX*(1-fg.parallaxX), tm.focusY - tm.focusCameraY*(1-fg.parallaxY)
+}
+
+func (tm *TileMap) updateForeground(dt float64) {
+	for _, fg := range tm.foreground {
+		target := float64(fg.layer.Opacity)
+		if fg.fade {
+			x, y := tm.layerFocus(fg)
+			if tm.layerCovers(fg.layer, x, y, tm.focusW, tm.focusH) {
+				target *= foregroundFadeAlpha
+			}
+		}
+		step := foregroundFadeSpeed * dt
+		fg.alpha += math.Max(-step, math.Min(step, target-fg.alpha))
+	}
+}
+
+// layerCovers reports whether any tile of the layer overlaps the box.
+func (tm *TileMap) layerCovers(layer *tiled.Layer, x, y, width, height float64) bool {
+	startX := max(int(x)/tm.TileWidth, 0)
+	startY := max(int(y)/tm.TileHeight, 0)
+	endX := min(int(x+width)/tm.TileWidth, tm.MapWidth-1)
+	endY := min(int(y+height)/tm.TileHeight, tm.MapHeight-1)
+	for ty := startY; ty <= endY; ty++ {
+		for tx := startX; tx <= endX; tx++ {
+			index := ty*tm.MapWidth + tx
+			if index < len(layer.Tiles) && tm.layerTileImage(layer.Tiles[index]) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DrawForeground draws the foreground layers; call it after the player.
+func (tm *TileMap) DrawForeground(screen *ebiten.Image, cameraX, cameraY float64) {
+	if tm.Map == nil || tm.chunks == nil {
+		return
+	}
+	for i, fg := range tm.foreground {
+		if fg.alpha <= 0 {
+			continue
+		}
+		tm.drawPass(screen, i+1, cameraX*fg.parallaxX, cameraY*fg.parallaxY, fg.alpha)
+	}
+	tm.evictChunks()
+}
@@ -2,6 +2,9 @@
 <tileset version="1.10" tiledversion="1.11.2" name="desert" tilewidth="400" tileheight="400" tilecount="17" columns="0">
  <grid orientation="orthogonal" width="1" height="1"/>
  <tile id="0">
+  <properties>
+   <property name="solid" type="bool" value="false"/>
+  </properties>
   <image source="empty.png" width="400" height="400"/>
  </tile>
  <tile id="1">
@@ -44,12 +47,21 @@
   <image source="13.png" width="32" height="32"/>
  </tile>
  <tile id="14">
+  <properties>
+   <property name="oneway" type="bool" value="true"/>
+  </properties>
   <image source="14.png" width="32" height="23"/>
  </tile>
  <tile id="15">
+  <properties>
+   <property name="oneway" type="bool" value="true"/>
+  </properties>
   <image source="15.png" width="32" height="23"/>
  </tile>
  <tile id="16">
+  <properties>
+   <property name="oneway" type="bool" value="true"/>
+  </properties>
   <image source="16.png" width="32" height="23"/>
  </tile>
 </tileset>
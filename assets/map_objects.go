@@ -0,0 +1,132 @@
+package assets
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lafriks/go-tiled"
+)
+
+type MapObject struct {
+	ID         uint32
+	Name       string
+	Type       string
+	Layer      string
+	X, Y       float64
+	Width      float64
+	Height     float64
+	Properties tiled.Properties
+	// Points holds the world positions of a polyline or polygon object.
+	Points []MapPoint
+}
+
+type MapPoint struct {
+	X, Y float64
+}
+
+func newMapObject(layer string, object *tiled.Object) MapObject {
+	objectType := object.Type
+	if objectType == "" {
+		objectType = object.Class
+	}
+
+	var points *tiled.Points
+	if len(object.PolyLines) > 0 {
+		points = object.PolyLines[0].Points
+	} else if len(object.Polygons) > 0 {
+		points = object.Polygons[0].Points
+	}
+	var mapPoints []MapPoint
+	if points != nil {
+		for _, point := range *points {
+			mapPoints = append(mapPoints, MapPoint{X: object.X + point.X, Y: object.Y + point.Y})
+		}
+	}
+
+	return MapObject{
+		ID:         object.ID,
+		Name:       object.Name,
+		Type:       objectType,
+		Layer:      layer,
+		X:          object.X,
+		Y:          object.Y,
+		Width:      object.Width,
+		Height:     object.Height,
+		Properties: object.Properties,
+		Points:     mapPoints,
+	}
+}
+
+func parseMapObjects(gameMap *tiled.Map) []MapObject {
+	var objects []MapObject
+	for _, group := range gameMap.ObjectGroups {
+		for _, object := range group.Objects {
+			objects = append(objects, newMapObject(group.Name, object))
+		}
+	}
+	return objects
+}
+
+func (tm *TileMap) ObjectsInLayer(layer string) []MapObject {
+	if tm == nil {
+		return nil
+	}
+
+	var objects []MapObject
+	for _, object := range tm.Objects {
+		if strings.EqualFold(object.Layer, layer) {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+func (tm *TileMap) ObjectsOfType(objectType string) []MapObject {
+	if tm == nil {
+		return nil
+	}
+
+	var objects []MapObject
+	for _, object := range tm.Objects {
+		if strings.EqualFold(object.Type, objectType) {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// ObjectByID returns the object with the given ID, as referenced by object
+// properties.
+func (tm *TileMap) ObjectByID(id uint32) (MapObject, bool) {
+	if tm == nil {
+		return MapObject{}, false
+	}
+
+	for _, object := range tm.Objects {
+		if object.ID == id {
+			return object, true
+		}
+	}
+	return MapObject{}, false
+}
+
+func (o MapObject) HasProperty(name string) bool {
+	return len(o.Properties.Get(name)) > 0
+}
+
+// ObjectRef returns the ID held by an object property. go-tiled's GetInt
+// only reads properties of type int, so object references need their own
+// lookup.
+func (o MapObject) ObjectRef(name string) (uint32, bool) {
+	for _, property := range o.Properties {
+		if property.Name == name && (property.Type == "object" || property.Type == "int") {
+			id, err := strconv.ParseUint(property.Value, 10, 32)
+			return uint32(id), err == nil && id != 0
+		}
+	}
+	return 0, false
+}
+
+func (o MapObject) Center() (x, y float64) {
+	return o.X + o.Width/2, o.Y + o.Height/2
+}
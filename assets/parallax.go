@@ -0,0 +1,81 @@
+package assets
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// layerParallax holds the parallaxx/parallaxy attributes of a map's layers
+// by layer ID, as written in the TMX file. go-tiled reads a missing
+// attribute as 0, which can't be told apart from an authored 0.
+type layerParallax map[uint32]parallaxAttrs
+
+type parallaxAttrs struct {
+	x, y       float64
+	hasX, hasY bool
+}
+
+func loadLayerParallax(mapPath string) layerParallax {
+	file, err := assets.Open(mapPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	parallax, _ := parseLayerParallax(file)
+	return parallax
+}
+
+func parseLayerParallax(r io.Reader) (layerParallax, error) {
+	parallax := make(layerParallax)
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return parallax, nil
+		}
+		if err != nil {
+			return parallax, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "layer", "imagelayer", "objectgroup", "group":
+		default:
+			continue
+		}
+
+		var id uint64
+		var attrs parallaxAttrs
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "id":
+				id, _ = strconv.ParseUint(attr.Value, 10, 32)
+			case "parallaxx":
+				attrs.x, _ = strconv.ParseFloat(attr.Value, 64)
+				attrs.hasX = true
+			case "parallaxy":
+				attrs.y, _ = strconv.ParseFloat(attr.Value, 64)
+				attrs.hasY = true
+			}
+		}
+		parallax[uint32(id)] = attrs
+	}
+}
+
+// factors returns the parallax of a layer the way Tiled applies it: a
+// missing attribute is 1, an authored one is used as is, 0 included.
+func (lp layerParallax) factors(id uint32) (x, y float64) {
+	attrs := lp[id]
+	x, y = 1, 1
+	if attrs.hasX {
+		x = attrs.x
+	}
+	if attrs.hasY {
+		y = attrs.y
+	}
+	return x, y
+}
@@ -0,0 +1,70 @@
+package assets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLayerParallax(t *testing.T) {
+	parallax, err := parseLayerParallax(strings.NewReader(`<map>
+ <imagelayer id="2" name="Bkg" parallaxx="0.1" parallaxy="0.05"/>
+ <layer id="1" name="Tiles"/>
+ <layer id="5" name="foreground fixed" parallaxx="0" parallaxy="0"/>
+ <layer id="6" name="foreground near" parallaxx="1.5"/>
+</map>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		id     uint32
+		px, py float64
+	}{
+		{"authored factors", 2, 0.1, 0.05},
+		{"missing attributes", 1, 1, 1},
+		{"authored zero", 5, 0, 0},
+		{"one axis authored", 6, 1.5, 1},
+		{"unknown layer", 99, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if px, py := parallax.factors(tt.id); px != tt.px || py != tt.py {
+				t.Errorf("factors = %v,%v, want %v,%v", px, py, tt.px, tt.py)
+			}
+		})
+	}
+
+	if px, py := loadLayerParallax(DesertMapPath).factors(2); px != 0.1 || py != 0.05 {
+		t.Errorf("desert background parallax = %v,%v, want 0.1,0.05", px, py)
+	}
+}
+
+func TestLayerFocusFollowsParallax(t *testing.T) {
+	tm := newTestTileMap("....")
+	tm.SetFocus(500, 200, 16, 32, 300, 100)
+
+	tests := []struct {
+		name   string
+		px, py float64
+		x, y   float64
+	}{
+		{"moves with the world", 1, 1, 500, 200},
+		{"fixed on screen", 0, 0, 200, 100},
+		{"slower than the world", 0.5, 1, 350, 200},
+		{"faster than the world", 2, 0, 800, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Where the player is drawn must be where the layer draws the
+			// returned position.
+			x, y := tm.layerFocus(&foregroundLayer{parallaxX: tt.px, parallaxY: tt.py})
+			if x != tt.x || y != tt.y {
+				t.Errorf("focus in layer = %v,%v, want %v,%v", x, y, tt.x, tt.y)
+			}
+			if screenX := 500.0 - 300; x-300*tt.px != screenX {
+				t.Errorf("layer draws the focus at x=%v, player is at %v", x-300*tt.px, screenX)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package assets
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+// RayHit is where a ray or a cast box first meets a blocking tile.
+type RayHit struct {
+	Hit bool
+	// X and Y are the point of contact for a ray, and where the box stops for
+	// a box cast.
+	X, Y             float64
+	NormalX, NormalY float64
+	Distance         float64
+	// GID is the global ID of the tile that was hit, found at TileX, TileY
+	// of Layer, as taken by SetTile and ClearTile.
+	GID                 uint32
+	TileX, TileY, Layer int
+	Info                TileCollisionInfo
+}
+
+// Raycast follows the segment between two positions and returns the first
+// solid tile on it. Positions use the same convention as CheckCollision and
+// BoxCast, so a ray and a box cast from the same point meet a floor at the
+// same height. One-way platforms only stop rays heading down from above
+// them, and shapes containing the start point are ignored.
+func (tm *TileMap) Raycast(fromX, fromY, toX, toY float64) RayHit {
+	hit := RayHit{X: toX, Y: toY, Distance: math.Hypot(toX-fromX, toY-fromY)}
+	if tm.CollisionSpace == nil {
+		return hit
+	}
+	dx, dy := toX-fromX, toY-fromY
+
+	area := resolv.NewRectangleFromCorners(math.Min(fromX, toX), math.Min(fromY, toY), math.Max(fromX, toX)+1, math.Max(fromY, toY)+1)
+	best := 1.0
+	for _, shape := range tm.shapesNear(area) {
+		tags := *shape.Tags()
+		if !tags.Has(TagSolid) {
+			continue
+		}
+		if tags.Has(TagOneWay) && (dy <= 0 || !canLandOn(shape, fromY)) {
+			continue
+		}
+		polygon, ok := shape.(*resolv.ConvexPolygon)
+		if !ok {
+			continue
+		}
+		t, nx, ny, ok := segmentPolygon(fromX, fromY, dx, dy, polygon)
+		if ok && (!hit.Hit || t < best) {
+			best = t
+			hit.Hit = true
+			hit.NormalX, hit.NormalY = nx, ny
+			hit.Info = ShapeInfo(shape)
+		}
+	}
+
+	if hit.Hit {
+		hit.X = fromX + dx*best
+		hit.Y = fromY + dy*best
+		hit.Distance *= best
+		tm.findHitTile(&hit, hit.X-hit.NormalX/2, hit.Y-hit.NormalY/2)
+	}
+	return hit
+}
+
+// BoxCast moves a box towards toX, toY with SweepBox and describes what
+// stops it. The box uses the same convention as CheckCollision.
+func (tm *TileMap) BoxCast(x, y, width, height, toX, toY float64) RayHit {
+	sweep := tm.SweepBox(x, y, width, height, toX-x, toY-y)
+	hit := RayHit{
+		Hit:      sweep.Hit,
+		X:        sweep.X,
+		Y:        sweep.Y,
+		NormalX:  sweep.NormalX,
+		NormalY:  sweep.NormalY,
+		Distance: math.Hypot(toX-x, toY-y) * sweep.Time,
+		Info:     sweep.Info,
+	}
+	if hit.Hit {
+		// The middle of the face that made contact, just inside the tile.
+		faceX := sweep.X - sweep.NormalX*(width/2+SweepSkin+0.5)
+		faceY := sweep.Y - sweep.NormalY*(height/2+SweepSkin+0.5)
+		tm.findHitTile(&hit, faceX, faceY)
+	}
+	return hit
+}
+
+// segmentPolygon clips a segment against a convex polygon and returns the
+// fraction of the segment at which it enters, with the entered edge's
+// normal.
+func segmentPolygon(x, y, dx, dy float64, polygon *resolv.ConvexPolygon) (t, normalX, normalY float64, ok bool) {
+	points := polygon.Transformed()
+	if len(points) < 3 {
+		return 0, 0, 0, false
+	}
+	var centerX, centerY float64
+	for _, point := range points {
+		centerX += point.X
+		centerY += point.Y
+	}
+	centerX /= float64(len(points))
+	centerY /= float64(len(points))
+
+	enter, exit := 0.0, 1.0
+	entered := false
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		nx, ny := b.Y-a.Y, a.X-b.X
+		if nx*(centerX-a.X)+ny*(centerY-a.Y) > 0 {
+			nx, ny = -nx, -ny
+		}
+
+		num := nx*(a.X-x) + ny*(a.Y-y)
+		denom := nx*dx + ny*dy
+		if denom == 0 {
+			if num < 0 {
+				return 0, 0, 0, false
+			}
+			continue
+		}
+		edgeT := num / denom
+		if denom < 0 {
+			if edgeT >= enter {
+				enter = edgeT
+				length := math.Hypot(nx, ny)
+				normalX, normalY = nx/length, ny/length
+				entered = true
+			}
+		} else {
+			exit = math.Min(exit, edgeT)
+		}
+		if enter > exit {
+			return 0, 0, 0, false
+		}
+	}
+	return enter, normalX, normalY, entered
+}
+
+// findHitTile fills in the colliding tile at a position given in the
+// convention of CheckCollision, where tile shapes sit half a tile up and
+// left of the drawn tiles.
+func (tm *TileMap) findHitTile(hit *RayHit, x, y float64) {
+	worldX, worldY := x+float64(tm.TileWidth)/2, y+float64(tm.TileHeight)/2
+	if tm.Map == nil || worldX < 0 || worldY < 0 {
+		return
+	}
+	tileX, tileY := int(worldX)/tm.TileWidth, int(worldY)/tm.TileHeight
+	if tileX >= tm.MapWidth || tileY >= tm.MapHeight {
+		return
+	}
+	for i, layer := range tm.Map.Layers {
+		index := tileY*tm.MapWidth + tileX
+		if index >= len(layer.Tiles) || isForegroundLayer(layer) {
+			continue
+		}
+		if tm.layerTileInfo(layer.Tiles[index]).HasShape() {
+			hit.GID = tm.TileGIDAt(tileX, tileY, i)
+			hit.TileX, hit.TileY, hit.Layer = tileX, tileY, i
+			return
+		}
+	}
+}
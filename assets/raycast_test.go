@@ -0,0 +1,112 @@
+package assets
+
+import (
+	"math"
+	"testing"
+)
+
+// castRows is the fixture for ray and box casts. In the convention of
+// CheckCollision the tiles sit half a tile up and left of where they are
+// drawn: the platform covers x 48-176 and y 16-48, the wall starts at
+// x=240 and the floor at y=80.
+var castRows = []string{
+	"........#",
+	"..====..#",
+	"........#",
+	"#########",
+}
+
+func TestRaycast(t *testing.T) {
+	tm := newTestTileMap(castRows...)
+
+	tests := []struct {
+		name                   string
+		fromX, fromY, toX, toY float64
+		hit                    bool
+		x, y                   float64
+		nx, ny                 float64
+		gid                    uint32
+		tileX, tileY           int
+		oneWay                 bool
+	}{
+		{"down onto floor", 40, 0, 40, 200, true, 40, 80, 0, -1, 2, 1, 3, false},
+		{"right into wall", 10, 60, 400, 60, true, 240, 60, -1, 0, 2, 8, 2, false},
+		{"down onto platform", 100, 0, 100, 200, true, 100, 16, 0, -1, 3, 3, 1, true},
+		{"up through platform", 100, 70, 100, 0, false, 100, 0, 0, 0, 0, 0, 0, false},
+		{"along platform", 10, 32, 230, 32, false, 230, 32, 0, 0, 0, 0, 0, false},
+		{"stops short of floor", 40, 0, 40, 70, false, 40, 70, 0, 0, 0, 0, 0, false},
+		{"diagonal onto floor", 0, 16, 128, 144, true, 64, 80, 0, -1, 2, 2, 3, false},
+		{"misses everything", 10, 10, 230, 5, false, 230, 5, 0, 0, 0, 0, 0, false},
+		{"starts inside floor", 40, 90, 40, 110, false, 40, 110, 0, 0, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit := tm.Raycast(tt.fromX, tt.fromY, tt.toX, tt.toY)
+			if hit.Hit != tt.hit {
+				t.Fatalf("hit = %v, want %v (%+v)", hit.Hit, tt.hit, hit)
+			}
+			if math.Abs(hit.X-tt.x) > 1e-6 || math.Abs(hit.Y-tt.y) > 1e-6 {
+				t.Errorf("point = (%v,%v), want (%v,%v)", hit.X, hit.Y, tt.x, tt.y)
+			}
+			wantDistance := math.Hypot(tt.x-tt.fromX, tt.y-tt.fromY)
+			if math.Abs(hit.Distance-wantDistance) > 1e-6 {
+				t.Errorf("distance = %v, want %v", hit.Distance, wantDistance)
+			}
+			if !tt.hit {
+				return
+			}
+			if hit.NormalX != tt.nx || hit.NormalY != tt.ny {
+				t.Errorf("normal = (%v,%v), want (%v,%v)", hit.NormalX, hit.NormalY, tt.nx, tt.ny)
+			}
+			if hit.GID != tt.gid || hit.TileX != tt.tileX || hit.TileY != tt.tileY || hit.Info.OneWay != tt.oneWay {
+				t.Errorf("tile %d at %d,%d one-way %v, want %d at %d,%d %v",
+					hit.GID, hit.TileX, hit.TileY, hit.Info.OneWay, tt.gid, tt.tileX, tt.tileY, tt.oneWay)
+			}
+		})
+	}
+}
+
+func TestBoxCast(t *testing.T) {
+	tm := newTestTileMap(castRows...)
+
+	hit := tm.BoxCast(40, 20, 16, 16, 40, 200)
+	if !hit.Hit || hit.GID != 2 || hit.TileX != 1 || hit.TileY != 3 {
+		t.Fatalf("box cast onto floor = %+v, want a hit on tile 2 at 1,3", hit)
+	}
+	if want := 72 - SweepSkin; math.Abs(hit.Y-want) > 1e-9 {
+		t.Errorf("box stops at y=%v, want %v", hit.Y, want)
+	}
+
+	if miss := tm.BoxCast(40, 20, 16, 16, 40, 50); miss.Hit || miss.Y != 50 {
+		t.Errorf("short box cast = %+v, want a miss ending at y=50", miss)
+	}
+}
+
+func TestRayAndBoxCastAgree(t *testing.T) {
+	tm := newTestTileMap(castRows...)
+
+	for _, x := range []float64{40, 100, 200} {
+		ray := tm.Raycast(x, 0, x, 200)
+		box := tm.BoxCast(x, 0, 2, 2, x, 200)
+		if !ray.Hit || !box.Hit {
+			t.Fatalf("x=%v: ray hit %v, box hit %v", x, ray.Hit, box.Hit)
+		}
+		if bottom := box.Y + 1; math.Abs(bottom-ray.Y) > SweepSkin+1e-9 {
+			t.Errorf("x=%v: box rests at y=%v, ray hits at y=%v", x, bottom, ray.Y)
+		}
+		if ray.GID != box.GID || ray.TileX != box.TileX || ray.TileY != box.TileY {
+			t.Errorf("x=%v: ray hits tile %d at %d,%d, box tile %d at %d,%d",
+				x, ray.GID, ray.TileX, ray.TileY, box.GID, box.TileX, box.TileY)
+		}
+	}
+
+	// The hit tile can be cleared directly.
+	hit := tm.Raycast(40, 0, 40, 200)
+	if err := tm.ClearTile(hit.TileX, hit.TileY, hit.Layer); err != nil {
+		t.Fatal(err)
+	}
+	if again := tm.Raycast(40, 0, 40, 200); again.Hit {
+		t.Errorf("ray still hits after clearing the tile: %+v", again)
+	}
+}
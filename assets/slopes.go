@@ -0,0 +1,96 @@
+package assets
+
+import (
+	"github.com/lafriks/go-tiled"
+	"github.com/solarlune/resolv"
+)
+
+// Values of the slope tile property. Both fill the full tile diagonally.
+const (
+	SlopeUp   = "up"   // floor rises to the right
+	SlopeDown = "down" // floor falls to the right
+)
+
+// tileOutline is a convex collision outline as x, y pairs in pixels,
+// relative to the tile's top-left corner.
+type tileOutline []float64
+
+// parseTileOutlines reads the shapes drawn in Tiled's tile collision editor,
+// keyed by global tile ID. Rectangles and polygons are supported.
+func parseTileOutlines(gameMap *tiled.Map) map[uint32][]tileOutline {
+	outlines := make(map[uint32][]tileOutline)
+	for _, tileset := range gameMap.Tilesets {
+		for _, tile := range tileset.Tiles {
+			for _, group := range tile.ObjectGroups {
+				for _, object := range group.Objects {
+					if outline := objectOutline(object); outline != nil {
+						gid := tileset.FirstGID + tile.ID
+						outlines[gid] = append(outlines[gid], outline)
+					}
+				}
+			}
+		}
+	}
+	return outlines
+}
+
+func objectOutline(object *tiled.Object) tileOutline {
+	if len(object.Polygons) > 0 && object.Polygons[0].Points != nil {
+		var outline tileOutline
+		for _, point := range *object.Polygons[0].Points {
+			outline = append(outline, object.X+point.X, object.Y+point.Y)
+		}
+		return outline
+	}
+	if object.Width > 0 && object.Height > 0 && len(object.Ellipses) == 0 {
+		x, y, w, h := object.X, object.Y, object.Width, object.Height
+		return tileOutline{x, y, x + w, y, x + w, y + h, x, y + h}
+	}
+	return nil
+}
+
+func slopeOutline(slope string, width, height float64) tileOutline {
+	switch slope {
+	case SlopeUp:
+		return tileOutline{0, height, width, 0, width, height}
+	case SlopeDown:
+		return tileOutline{0, 0, width, height, 0, height}
+	}
+	return nil
+}
+
+// layerTileOutlines returns the non-rectangular collision outlines of a
+// placed tile, or nil when it collides as a plain block.
+func (tm *TileMap) layerTileOutlines(tile *tiled.LayerTile, info TileCollisionInfo) []tileOutline {
+	if tile.Nil || tile.Tileset == nil {
+		return nil
+	}
+	if outlines := tm.tileOutlines[tile.Tileset.FirstGID+tile.ID]; len(outlines) > 0 {
+		return outlines
+	}
+	if outline := slopeOutline(info.Slope, float64(tm.TileWidth), float64(tm.TileHeight)); outline != nil {
+		return []tileOutline{outline}
+	}
+	return nil
+}
+
+// outlineShape places an outline on the tile grid with the same offset as
+// the rectangular tile shapes.
+func (tm *TileMap) outlineShape(tileX, tileY int, outline tileOutline) *resolv.ConvexPolygon {
+	left, top := tm.tileShapeOrigin(tileX, tileY)
+	return resolv.NewConvexPolygon(left, top, outline)
+}
+
+// SlopeAt reports whether the box touches a sloped or polygonal surface.
+func (tm *TileMap) SlopeAt(x, y, width, height float64) bool {
+	if tm.CollisionSpace == nil {
+		return false
+	}
+	checkRect := resolv.NewRectangle(x, y, width, height)
+	for _, shape := range tm.shapesNear(checkRect) {
+		if shape.Tags().Has(TagSlope) && overlaps(checkRect, shape) {
+			return true
+		}
+	}
+	return false
+}
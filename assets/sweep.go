@@ -0,0 +1,143 @@
+package assets
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+// SweepSkin is the gap a swept box keeps from whatever stopped it. Shapes
+// that merely touch count as overlapping, so a box resting flush on the
+// ground could not slide along it.
+const SweepSkin = 0.01
+
+// slopeSweepRefinements is how many times the contact with a slope is
+// bisected after the sampled sweep first overlaps it.
+const slopeSweepRefinements = 10
+
+// SweepHit describes the first shape a moving box runs into.
+type SweepHit struct {
+	Hit bool
+	// Time is the completed fraction of the move, from 0 to 1.
+	Time             float64
+	NormalX, NormalY float64
+	// X and Y are where the box comes to rest, in the caller's coordinates.
+	X, Y float64
+	Info TileCollisionInfo
+}
+
+// SweepBox moves a box by dx, dy and reports the first blocking tile shape
+// on the way, so fast boxes cannot skip through thin ones. The box uses the
+// same convention as CheckCollision. One-way platforms only stop downward
+// moves that start above them, and shapes the box already overlaps are
+// ignored so it can always move out of them.
+func (tm *TileMap) SweepBox(x, y, width, height, dx, dy float64) SweepHit {
+	hit := SweepHit{Time: 1, X: x + dx, Y: y + dy}
+	if tm.CollisionSpace == nil || (dx == 0 && dy == 0) {
+		return hit
+	}
+
+	start := resolv.NewRectangle(x, y, width, height)
+	startBounds := start.Bounds()
+	path := resolv.NewRectangle(x+dx/2, y+dy/2, width+math.Abs(dx), height+math.Abs(dy))
+	for _, shape := range tm.shapesNear(path) {
+		tags := *shape.Tags()
+		if !tags.Has(TagSolid) {
+			continue
+		}
+		if tags.Has(TagOneWay) && (dy <= 0 || !canLandOn(shape, startBounds.Max.Y)) {
+			continue
+		}
+
+		var t, nx, ny float64
+		var ok bool
+		if tags.Has(TagSlope) {
+			t, nx, ny, ok = sweepSampled(x, y, width, height, dx, dy, shape)
+		} else {
+			bounds := shape.Bounds()
+			t, nx, ny, ok = SweepAABB(startBounds.Min.X, startBounds.Min.Y, width, height, dx, dy,
+				bounds.Min.X, bounds.Min.Y, bounds.Width(), bounds.Height())
+		}
+		if ok && (!hit.Hit || t < hit.Time) {
+			hit = SweepHit{Hit: true, Time: t, NormalX: nx, NormalY: ny, Info: ShapeInfo(shape)}
+		}
+	}
+
+	if hit.Hit {
+		hit.X = x + dx*hit.Time + hit.NormalX*SweepSkin
+		hit.Y = y + dy*hit.Time + hit.NormalY*SweepSkin
+	}
+	return hit
+}
+
+// SweepAABB returns when a box at ax, ay moving by dx, dy first touches a
+// static box, and the normal of the face it touches. Both boxes are given by
+// their top-left corner. Boxes that already overlap do not count as a hit.
+func SweepAABB(ax, ay, aw, ah, dx, dy, bx, by, bw, bh float64) (t, normalX, normalY float64, ok bool) {
+	xEntry, xExit := sweepAxis(ax, ax+aw, bx, bx+bw, dx)
+	yEntry, yExit := sweepAxis(ay, ay+ah, by, by+bh, dy)
+
+	entry := math.Max(xEntry, yEntry)
+	exit := math.Min(xExit, yExit)
+	if entry >= exit || entry < 0 || entry > 1 {
+		return 0, 0, 0, false
+	}
+
+	if xEntry > yEntry {
+		return entry, -math.Copysign(1, dx), 0, true
+	}
+	return entry, 0, -math.Copysign(1, dy), true
+}
+
+// sweepAxis returns the fractions of the move at which two intervals start
+// and stop overlapping along one axis.
+func sweepAxis(aMin, aMax, bMin, bMax, d float64) (entry, exit float64) {
+	switch {
+	case d > 0:
+		return (bMin - aMax) / d, (bMax - aMin) / d
+	case d < 0:
+		return (bMax - aMin) / d, (bMin - aMax) / d
+	case aMax > bMin && aMin < bMax:
+		return math.Inf(-1), math.Inf(1)
+	default:
+		return math.Inf(1), math.Inf(-1)
+	}
+}
+
+// sweepSampled finds the contact with a non-rectangular shape by stepping
+// the box along the move in steps of half its smaller side, then bisecting
+// the step where it first overlaps.
+func sweepSampled(x, y, width, height, dx, dy float64, shape resolv.IShape) (t, normalX, normalY float64, ok bool) {
+	overlapsAt := func(t float64) bool {
+		return overlaps(resolv.NewRectangle(x+dx*t, y+dy*t, width, height), shape)
+	}
+	if overlapsAt(0) {
+		return 0, 0, 0, false
+	}
+
+	steps := max(int(math.Ceil(math.Hypot(dx, dy)/(math.Min(width, height)/2))), 1)
+	free := 0.0
+	for i := 1; i <= steps; i++ {
+		blocked := float64(i) / float64(steps)
+		if !overlapsAt(blocked) {
+			free = blocked
+			continue
+		}
+		for range slopeSweepRefinements {
+			mid := (free + blocked) / 2
+			if overlapsAt(mid) {
+				blocked = mid
+			} else {
+				free = mid
+			}
+		}
+		if dy > 0 {
+			return free, 0, -1, true
+		}
+		if dx != 0 {
+			return free, -math.Copysign(1, dx), 0, true
+		}
+		return free, 0, 1, true
+	}
+	return 0, 0, 0, false
+}
@@ -0,0 +1,91 @@
+package assets
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSweepAABB(t *testing.T) {
+	type box struct{ x, y, w, h float64 }
+	tests := []struct {
+		name   string
+		a      box
+		dx, dy float64
+		b      box
+		hit    bool
+		t      float64
+		nx, ny float64
+	}{
+		{"head-on from the left", box{0, 0, 10, 10}, 20, 0, box{20, 0, 10, 10}, true, 0.5, -1, 0},
+		{"head-on from above", box{0, 0, 10, 10}, 0, 40, box{0, 30, 10, 10}, true, 0.5, 0, -1},
+		{"moving away", box{0, 0, 10, 10}, -20, 0, box{20, 0, 10, 10}, false, 0, 0, 0},
+		{"stops short", box{0, 0, 10, 10}, 5, 0, box{20, 0, 10, 10}, false, 0, 0, 0},
+		{"passes beside", box{0, 0, 10, 10}, 40, 0, box{20, 20, 10, 10}, false, 0, 0, 0},
+		{"diagonal into the side", box{0, 0, 10, 10}, 20, 10, box{20, 0, 10, 20}, true, 0.5, -1, 0},
+		// Reaching both faces at once resolves onto the top face, so a box
+		// landing exactly on a ledge corner stands on it.
+		{"exact corner", box{0, 0, 10, 10}, 20, 20, box{20, 20, 10, 10}, true, 0.5, 0, -1},
+		{"corner near miss", box{0, 0, 10, 10}, 20, 5, box{20, 20, 10, 10}, false, 0, 0, 0},
+		{"touching and moving in", box{0, 0, 10, 10}, 5, 0, box{10, 0, 10, 10}, true, 0, -1, 0},
+		{"touching and sliding along", box{0, 0, 10, 10}, 30, 0, box{0, 10, 40, 10}, false, 0, 0, 0},
+		{"already overlapping", box{5, 0, 10, 10}, 5, 0, box{10, 0, 10, 10}, false, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm, nx, ny, ok := SweepAABB(tt.a.x, tt.a.y, tt.a.w, tt.a.h, tt.dx, tt.dy, tt.b.x, tt.b.y, tt.b.w, tt.b.h)
+			if ok != tt.hit {
+				t.Fatalf("hit = %v, want %v", ok, tt.hit)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(tm-tt.t) > 1e-9 || nx != tt.nx || ny != tt.ny {
+				t.Errorf("got t=%v normal=(%v,%v), want t=%v normal=(%v,%v)", tm, nx, ny, tt.t, tt.nx, tt.ny)
+			}
+		})
+	}
+}
+
+func TestSweepBox(t *testing.T) {
+	// Row 1 is a one-way platform and row 3 solid floor. Tile shapes sit half
+	// a tile up and left, so the platform's top is at y=16 and the floor's at
+	// y=80; boxes are given by their centre.
+	tm := newTestTileMap(
+		"........",
+		"..====..",
+		"........",
+		"########",
+	)
+
+	tests := []struct {
+		name   string
+		x, y   float64
+		dx, dy float64
+		hit    bool
+		oneWay bool
+		wantY  float64
+	}{
+		{"lands on platform", 80, -20, 0, 50, true, true, 8 - SweepSkin},
+		{"jumps up through platform", 80, 60, 0, -70, false, false, -10},
+		{"falls from inside platform", 80, 30, 0, 30, false, false, 60},
+		{"lands on floor beside platform", 16, 20, 0, 60, true, false, 72 - SweepSkin},
+		{"resting flush on floor", 16, 72, 0, 5, true, false, 72 - SweepSkin},
+		{"no move", 16, 20, 0, 0, false, false, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit := tm.SweepBox(tt.x, tt.y, 16, 16, tt.dx, tt.dy)
+			if hit.Hit != tt.hit {
+				t.Fatalf("hit = %v, want %v (%+v)", hit.Hit, tt.hit, hit)
+			}
+			if hit.Hit && hit.Info.OneWay != tt.oneWay {
+				t.Errorf("one-way = %v, want %v", hit.Info.OneWay, tt.oneWay)
+			}
+			if math.Abs(hit.Y-tt.wantY) > 1e-9 {
+				t.Errorf("y = %v, want %v", hit.Y, tt.wantY)
+			}
+		})
+	}
+}
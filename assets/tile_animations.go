@@ -0,0 +1,82 @@
+package assets
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lafriks/go-tiled"
+)
+
+type animationFrame struct {
+	gid      uint32
+	duration float64
+}
+
+// tileAnimation is a tile's frame animation as authored in the tileset.
+type tileAnimation struct {
+	frames []animationFrame
+	length float64
+}
+
+// animatedTile is an animated tile placed in a chunk, in tile coordinates.
+type animatedTile struct {
+	x, y int
+	gid  uint32
+}
+
+// parseTileAnimations reads the tilesets' frame animations, keyed by the
+// global ID of the animated tile.
+func parseTileAnimations(gameMap *tiled.Map) map[uint32]*tileAnimation {
+	animations := make(map[uint32]*tileAnimation)
+	for _, tileset := range gameMap.Tilesets {
+		for _, tile := range tileset.Tiles {
+			if len(tile.Animation) == 0 {
+				continue
+			}
+			animation := &tileAnimation{}
+			for _, frame := range tile.Animation {
+				duration := float64(frame.Duration) / 1000
+				animation.frames = append(animation.frames, animationFrame{
+					gid:      tileset.FirstGID + frame.TileID,
+					duration: duration,
+				})
+				animation.length += duration
+			}
+			if animation.length > 0 {
+				animations[tileset.FirstGID+tile.ID] = animation
+			}
+		}
+	}
+	return animations
+}
+
+// frameAt returns the tile shown t seconds into the animation.
+func (a *tileAnimation) frameAt(t float64) uint32 {
+	t -= float64(int(t/a.length)) * a.length
+	for _, frame := range a.frames {
+		if t < frame.duration {
+			return frame.gid
+		}
+		t -= frame.duration
+	}
+	return a.frames[len(a.frames)-1].gid
+}
+
+// Update advances the tile animations and foreground fades. It runs on the
+// simulation tick so replays show the same frames.
+func (tm *TileMap) Update(dt float64) {
+	tm.animationTime += dt
+	tm.updateForeground(dt)
+}
+
+// drawAnimatedTiles draws a chunk's animated tiles over its cached image.
+func (tm *TileMap) drawAnimatedTiles(screen *ebiten.Image, chunk *tileChunk, cameraX, cameraY, alpha float64) {
+	for _, tile := range chunk.animated {
+		tileImage := tm.tileImages[tm.tileAnimations[tile.gid].frameAt(tm.animationTime)]
+		if tileImage == nil {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(tile.x*tm.TileWidth)-cameraX, float64(tile.y*tm.TileHeight)-cameraY)
+		op.ColorScale.ScaleAlpha(float32(alpha))
+		screen.DrawImage(tileImage, op)
+	}
+}
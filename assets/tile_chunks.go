@@ -0,0 +1,141 @@
+package assets
+
+import (
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lafriks/go-tiled"
+)
+
+const (
+	// chunkTiles is the width and height of a render chunk in tiles.
+	chunkTiles = 16
+	// maxCachedChunks bounds the rendered chunks kept in memory. A 1280x720
+	// view over 32px tiles touches at most 12 of them.
+	maxCachedChunks = 48
+)
+
+type chunkKey struct {
+	pass, x, y int
+}
+
+// tileChunk is a pre-rendered block of the map's static tiles. Animated
+// tiles are left out of the image and drawn on top every frame.
+type tileChunk struct {
+	image    *ebiten.Image
+	animated []animatedTile
+	lastDraw uint64
+}
+
+// Draw renders the chunks intersecting the view, rendering missing ones on
+// demand and dropping the least recently drawn once too many are cached.
+// Foreground layers are left for DrawForeground.
+func (tm *TileMap) Draw(screen *ebiten.Image, cameraX, cameraY float64) {
+	if tm.Map == nil || tm.chunks == nil {
+		return
+	}
+	tm.drawFrame++
+	tm.drawPass(screen, 0, cameraX, cameraY, 1)
+	tm.evictChunks()
+}
+
+func (tm *TileMap) drawPass(screen *ebiten.Image, pass int, cameraX, cameraY, alpha float64) {
+	chunkWidth := float64(chunkTiles * tm.TileWidth)
+	chunkHeight := float64(chunkTiles * tm.TileHeight)
+	view := screen.Bounds()
+	startX := max(int(cameraX/chunkWidth), 0)
+	startY := max(int(cameraY/chunkHeight), 0)
+	endX := min(int((cameraX+float64(view.Dx()))/chunkWidth), tm.chunkColumns()-1)
+	endY := min(int((cameraY+float64(view.Dy()))/chunkHeight), tm.chunkRows()-1)
+
+	for cy := startY; cy <= endY; cy++ {
+		for cx := startX; cx <= endX; cx++ {
+			chunk := tm.chunk(chunkKey{pass, cx, cy})
+			chunk.lastDraw = tm.drawFrame
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(cx)*chunkWidth-cameraX, float64(cy)*chunkHeight-cameraY)
+			op.ColorScale.ScaleAlpha(float32(alpha))
+			screen.DrawImage(chunk.image, op)
+			tm.drawAnimatedTiles(screen, chunk, cameraX, cameraY, alpha)
+		}
+	}
+}
+
+func (tm *TileMap) chunkColumns() int {
+	return (tm.MapWidth + chunkTiles - 1) / chunkTiles
+}
+
+func (tm *TileMap) chunkRows() int {
+	return (tm.MapHeight + chunkTiles - 1) / chunkTiles
+}
+
+func (tm *TileMap) chunk(key chunkKey) *tileChunk {
+	if chunk, ok := tm.chunks[key]; ok {
+		return chunk
+	}
+	chunk := tm.renderChunk(key)
+	tm.chunks[key] = chunk
+	return chunk
+}
+
+func (tm *TileMap) renderChunk(key chunkKey) *tileChunk {
+	chunk := &tileChunk{image: ebiten.NewImage(chunkTiles*tm.TileWidth, chunkTiles*tm.TileHeight)}
+	for _, layer := range tm.renderPassLayers(key.pass) {
+		for y := key.y * chunkTiles; y < min((key.y+1)*chunkTiles, tm.MapHeight); y++ {
+			for x := key.x * chunkTiles; x < min((key.x+1)*chunkTiles, tm.MapWidth); x++ {
+				tileIndex := y*tm.MapWidth + x
+				if tileIndex >= len(layer.Tiles) {
+					continue
+				}
+				tile := layer.Tiles[tileIndex]
+				if gid, ok := tm.animatedTileGID(tile); ok {
+					chunk.animated = append(chunk.animated, animatedTile{x: x, y: y, gid: gid})
+					continue
+				}
+				tileImage := tm.layerTileImage(tile)
+				if tileImage == nil {
+					continue
+				}
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64((x-key.x*chunkTiles)*tm.TileWidth), float64((y-key.y*chunkTiles)*tm.TileHeight))
+				chunk.image.DrawImage(tileImage, op)
+			}
+		}
+	}
+	return chunk
+}
+
+func (tm *TileMap) animatedTileGID(tile *tiled.LayerTile) (uint32, bool) {
+	if tile.Nil || tile.Tileset == nil {
+		return 0, false
+	}
+	gid := tile.Tileset.FirstGID + tile.ID
+	_, ok := tm.tileAnimations[gid]
+	return gid, ok
+}
+
+// layerTileImage returns the image of a placed tile. The first tile of the
+// tileset is the blank brush and is never drawn.
+func (tm *TileMap) layerTileImage(tile *tiled.LayerTile) *ebiten.Image {
+	if tile.Nil || tile.Tileset == nil || tile.ID == 0 {
+		return nil
+	}
+	return tm.tileImages[tile.Tileset.FirstGID+tile.ID]
+}
+
+func (tm *TileMap) evictChunks() {
+	if len(tm.chunks) <= maxCachedChunks {
+		return
+	}
+	keys := make([]chunkKey, 0, len(tm.chunks))
+	for key := range tm.chunks {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return tm.chunks[keys[i]].lastDraw < tm.chunks[keys[j]].lastDraw
+	})
+	for _, key := range keys[:len(keys)-maxCachedChunks] {
+		tm.chunks[key].image.Deallocate()
+		delete(tm.chunks, key)
+	}
+}
@@ -0,0 +1,126 @@
+package assets
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/lafriks/go-tiled"
+	"github.com/solarlune/resolv"
+)
+
+// SetTile places the tile with global ID gid at tile x, y of a layer and
+// updates the rendered chunk and the collision shapes around it. A gid of 0
+// clears the tile.
+func (tm *TileMap) SetTile(x, y, layer int, gid uint32) error {
+	if tm.Map == nil {
+		return fmt.Errorf("tilemap: no map loaded")
+	}
+	if layer < 0 || layer >= len(tm.Map.Layers) {
+		return fmt.Errorf("tilemap: no layer %d", layer)
+	}
+	if x < 0 || x >= tm.MapWidth || y < 0 || y >= tm.MapHeight {
+		return fmt.Errorf("tilemap: tile %d,%d is outside the map", x, y)
+	}
+	tile, err := tm.Map.TileGIDToTile(gid)
+	if err != nil {
+		return fmt.Errorf("tilemap: tile %d: %w", gid, err)
+	}
+
+	mapLayer := tm.Map.Layers[layer]
+	for len(mapLayer.Tiles) < tm.MapWidth*tm.MapHeight {
+		mapLayer.Tiles = append(mapLayer.Tiles, tiled.NilLayerTile)
+	}
+	mapLayer.Tiles[y*tm.MapWidth+x] = tile
+	tm.edited = true
+
+	if tm.CollisionSpace != nil && tm.collisionTiles != nil {
+		region := tm.removeCollisionShapes(layer, x, y)
+		tm.buildCollisionRegion(layer, region)
+	}
+	tm.invalidateChunks(x, y)
+	return nil
+}
+
+// Edited reports whether SetTile or ClearTile changed the map since it was
+// loaded or cloned.
+func (tm *TileMap) Edited() bool {
+	return tm.edited
+}
+
+// Clone returns a copy of the map whose tiles can be edited without touching
+// tm. The copy shares the images, objects and backgrounds of tm and builds its
+// own collision shapes.
+func (tm *TileMap) Clone() *TileMap {
+	gameMap := *tm.Map
+	gameMap.Layers = make([]*tiled.Layer, len(tm.Map.Layers))
+	for i, layer := range tm.Map.Layers {
+		copied := *layer
+		copied.Tiles = slices.Clone(layer.Tiles)
+		gameMap.Layers[i] = &copied
+	}
+
+	clone := &TileMap{
+		Map:            &gameMap,
+		TileWidth:      tm.TileWidth,
+		TileHeight:     tm.TileHeight,
+		MapWidth:       tm.MapWidth,
+		MapHeight:      tm.MapHeight,
+		PixelWidth:     tm.PixelWidth,
+		PixelHeight:    tm.PixelHeight,
+		CollisionSpace: resolv.NewSpace(tm.PixelWidth, tm.PixelHeight, tm.TileWidth, tm.TileHeight),
+		Objects:        tm.Objects,
+		Backgrounds:    tm.Backgrounds,
+		tileInfo:       tm.tileInfo,
+		tileOutlines:   tm.tileOutlines,
+		tileImages:     tm.tileImages,
+		chunks:         make(map[chunkKey]*tileChunk),
+		tileAnimations: tm.tileAnimations,
+		parallax:       tm.parallax,
+		foreground:     parseForegroundLayers(&gameMap, tm.parallax),
+	}
+	clone.createCollisionObjects()
+	return clone
+}
+
+// ClearTile removes the tile at tile x, y of a layer.
+func (tm *TileMap) ClearTile(x, y, layer int) error {
+	return tm.SetTile(x, y, layer, 0)
+}
+
+// TileGIDAt returns the global ID of the tile at tile x, y of a layer, or 0
+// for an empty or missing tile.
+func (tm *TileMap) TileGIDAt(x, y, layer int) uint32 {
+	if tm.Map == nil || layer < 0 || layer >= len(tm.Map.Layers) || x < 0 || x >= tm.MapWidth || y < 0 || y >= tm.MapHeight {
+		return 0
+	}
+	tiles := tm.Map.Layers[layer].Tiles
+	index := y*tm.MapWidth + x
+	if index >= len(tiles) || tiles[index].Nil || tiles[index].Tileset == nil {
+		return 0
+	}
+	return tiles[index].Tileset.FirstGID + tiles[index].ID
+}
+
+// LayerIndex returns the index of the tile layer with the given name.
+func (tm *TileMap) LayerIndex(name string) (int, bool) {
+	if tm.Map == nil {
+		return 0, false
+	}
+	for i, layer := range tm.Map.Layers {
+		if layer.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// invalidateChunks drops the rendered chunks showing tile x, y so they are
+// rendered again with the new tile.
+func (tm *TileMap) invalidateChunks(x, y int) {
+	for key, chunk := range tm.chunks {
+		if key.x == x/chunkTiles && key.y == y/chunkTiles {
+			chunk.image.Deallocate()
+			delete(tm.chunks, key)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package assets
+
+import (
+	"github.com/lafriks/go-tiled"
+	"github.com/solarlune/resolv"
+)
+
+// oneWayTolerance is how far a box may already overlap a one-way platform
+// and still land on it.
+const oneWayTolerance = 1.0
+
+// Collision shape tags, so queries can filter shapes without unpacking their
+// TileCollisionInfo.
+const (
+	TagSolid resolv.Tags = 1 << iota
+	TagOneWay
+	TagHazard
+	TagSlope
+)
+
+// TileCollisionInfo is how a tile behaves on contact. It is read from the
+// custom properties of the tileset's tiles:
+//
+//	solid    bool   blocks movement (default: every tile except the first)
+//	oneway   bool   only blocks from above, so it can be jumped through
+//	hazard   int    damage dealt on touch
+//	friction float  ground friction multiplier, below 1 is slippery
+//	bounce   float  share of the landing speed returned upwards
+//	slope    string SlopeUp or SlopeDown for a full diagonal tile
+//
+// Tiles with shapes drawn in the tile collision editor use those instead of
+// a full block.
+type TileCollisionInfo struct {
+	ID       uint32
+	Solid    bool
+	OneWay   bool
+	Hazard   int
+	Friction float64
+	Bounce   float64
+	Slope    string
+}
+
+// HasShape reports whether the tile needs a collision shape at all.
+func (info TileCollisionInfo) HasShape() bool {
+	return info.Solid || info.Hazard > 0
+}
+
+func (info TileCollisionInfo) tags() resolv.Tags {
+	var tags resolv.Tags
+	if info.Solid {
+		tags.Set(TagSolid)
+	}
+	if info.OneWay {
+		tags.Set(TagOneWay)
+	}
+	if info.Hazard > 0 {
+		tags.Set(TagHazard)
+	}
+	return tags
+}
+
+func defaultTileInfo(tileID uint32) TileCollisionInfo {
+	return TileCollisionInfo{
+		ID:       tileID,
+		Solid:    IsTileSolid(tileID),
+		Friction: 1,
+	}
+}
+
+func tileInfoFromProperties(tileID uint32, properties tiled.Properties) TileCollisionInfo {
+	info := defaultTileInfo(tileID)
+	if len(properties.Get("solid")) > 0 {
+		info.Solid = properties.GetBool("solid")
+	}
+	if len(properties.Get("oneway")) > 0 {
+		info.OneWay = properties.GetBool("oneway")
+	}
+	if len(properties.Get("hazard")) > 0 {
+		info.Hazard = properties.GetInt("hazard")
+	}
+	if len(properties.Get("friction")) > 0 {
+		info.Friction = properties.GetFloat("friction")
+	}
+	if len(properties.Get("bounce")) > 0 {
+		info.Bounce = properties.GetFloat("bounce")
+	}
+	info.Slope = properties.GetString("slope")
+	return info
+}
+
+// parseTileInfo collects the collision info of every tile that has custom
+// properties, keyed by global tile ID.
+func parseTileInfo(gameMap *tiled.Map) map[uint32]TileCollisionInfo {
+	infos := make(map[uint32]TileCollisionInfo)
+	for _, tileset := range gameMap.Tilesets {
+		for _, tile := range tileset.Tiles {
+			if len(tile.Properties) == 0 {
+				continue
+			}
+			infos[tileset.FirstGID+tile.ID] = tileInfoFromProperties(tile.ID, tile.Properties)
+		}
+	}
+	return infos
+}
+
+// layerTileInfo returns the collision info of a tile placed in a layer.
+func (tm *TileMap) layerTileInfo(tile *tiled.LayerTile) TileCollisionInfo {
+	if tile.Nil || tile.Tileset == nil {
+		return TileCollisionInfo{Friction: 1}
+	}
+	if info, ok := tm.tileInfo[tile.Tileset.FirstGID+tile.ID]; ok {
+		return info
+	}
+	return defaultTileInfo(tile.ID)
+}
+
+// ShapeInfo returns the collision info a shape was built from.
+func ShapeInfo(shape resolv.IShape) TileCollisionInfo {
+	if info, ok := shape.Data().(TileCollisionInfo); ok {
+		return info
+	}
+	return TileCollisionInfo{Solid: true, Friction: 1}
+}
@@ -12,7 +12,6 @@ import (
 )
 
 type TileMap struct {
-	Image          *ebiten.Image
 	Map            *tiled.Map
 	TileWidth      int
 	TileHeight     int
@@ -21,14 +20,56 @@ type TileMap struct {
 	PixelWidth     int
 	PixelHeight    int
 	CollisionSpace *resolv.Space
+	Objects        []MapObject
+	Backgrounds    []BackgroundLayer
+
+	collisionTiles map[collisionTileKey][]*resolv.ConvexPolygon
+	shapeRects     map[*resolv.ConvexPolygon]tileRect
+	nearShapes     []resolv.IShape
+	tileInfo       map[uint32]TileCollisionInfo
+	tileOutlines   map[uint32][]tileOutline
+
+	tileImages map[uint32]*ebiten.Image
+	chunks     map[chunkKey]*tileChunk
+	drawFrame  uint64
+
+	tileAnimations map[uint32]*tileAnimation
+	animationTime  float64
+	edited         bool
+
+	parallax                       layerParallax
+	foreground                     []*foregroundLayer
+	focusX, focusY, focusW, focusH float64
+	focusCameraX, focusCameraY     float64
 }
 
+const (
+	DesertMapPath = "images/backgrounds/desert-tiles/desert.tmx"
+	RuinsMapPath  = "images/backgrounds/desert-tiles/ruins.tmx"
+)
+
 var (
 	DesertTileMap *TileMap
+
+	tileMapCache = make(map[string]*TileMap)
 )
 
 func InitTileMaps() {
-	DesertTileMap = LoadTileMap("images/backgrounds/desert-tiles/desert.tmx")
+	DesertTileMap = GetTileMap(DesertMapPath)
+}
+
+// GetTileMap loads a map once and returns the cached copy afterwards. The
+// copy is shared, so callers that edit tiles should Clone it first.
+func GetTileMap(mapPath string) *TileMap {
+	if tileMap, ok := tileMapCache[mapPath]; ok {
+		return tileMap
+	}
+
+	tileMap := LoadTileMap(mapPath)
+	if tileMap != nil {
+		tileMapCache[mapPath] = tileMap
+	}
+	return tileMap
 }
 
 func LoadTileMap(mapPath string) *TileMap {
@@ -49,13 +90,22 @@ func LoadTileMap(mapPath string) *TileMap {
 		CollisionSpace: resolv.NewSpace(gameMap.Width*gameMap.TileWidth, gameMap.Height*gameMap.TileHeight, gameMap.TileWidth, gameMap.TileHeight),
 	}
 
-	tileMap.Image = renderTileMapToImage(gameMap, mapPath)
+	tileMap.tileImages = loadTileImages(gameMap, mapPath)
+	tileMap.tileAnimations = parseTileAnimations(gameMap)
+	tileMap.parallax = loadLayerParallax(mapPath)
+	tileMap.foreground = parseForegroundLayers(gameMap, tileMap.parallax)
+	tileMap.chunks = make(map[chunkKey]*tileChunk)
+	tileMap.tileInfo = parseTileInfo(gameMap)
+	tileMap.tileOutlines = parseTileOutlines(gameMap)
 	tileMap.createCollisionObjects()
+	tileMap.Objects = parseMapObjects(gameMap)
+	tileMap.Backgrounds = loadBackgroundLayers(gameMap, mapPath, tileMap.parallax)
 	return tileMap
 }
 
-func renderTileMapToImage(gameMap *tiled.Map, mapPath string) *ebiten.Image {
-	mapImage := ebiten.NewImage(gameMap.Width*gameMap.TileWidth, gameMap.Height*gameMap.TileHeight)
+// loadTileImages slices every tileset into per-tile images, keyed by
+// global tile ID.
+func loadTileImages(gameMap *tiled.Map, mapPath string) map[uint32]*ebiten.Image {
 	tileImages := make(map[uint32]*ebiten.Image)
 
 	for _, tileset := range gameMap.Tilesets {
@@ -92,67 +142,7 @@ func renderTileMapToImage(gameMap *tiled.Map, mapPath string) *ebiten.Image {
 			}
 		}
 	}
-	for _, layer := range gameMap.Layers {
-		if len(layer.Tiles) > 0 && layer.Visible {
-			renderTileLayerFromImages(mapImage, layer, gameMap, tileImages)
-		}
-	}
-	return mapImage
-}
-
-func renderTileLayerFromImages(mapImage *ebiten.Image, layer *tiled.Layer, gameMap *tiled.Map, tileImages map[uint32]*ebiten.Image) {
-	for y := 0; y < gameMap.Height; y++ {
-		for x := 0; x < gameMap.Width; x++ {
-			tileIndex := y*gameMap.Width + x
-			if tileIndex >= len(layer.Tiles) {
-				continue
-			}
-			tile := layer.Tiles[tileIndex]
-			if tile.ID == 0 {
-				continue
-			}
-			correctedTileID := tile.ID + 1
-			tileImage := tileImages[correctedTileID]
-			if tileImage == nil {
-				continue
-			}
-			dstX := x * gameMap.TileWidth
-			dstY := y * gameMap.TileHeight
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(dstX), float64(dstY))
-			mapImage.DrawImage(tileImage, op)
-		}
-	}
-}
-
-func (tm *TileMap) Draw(screen *ebiten.Image, cameraX, cameraY float64) {
-	if tm.Image == nil {
-		return
-	}
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(-cameraX, -cameraY)
-	screen.DrawImage(tm.Image, op)
-}
-
-func (tm *TileMap) drawTiled(screen *ebiten.Image, cameraX, cameraY, screenWidth, screenHeight float64) {
-	if tm.Image == nil {
-		return
-	}
-	tileMapWidth := float64(tm.PixelWidth)
-	tileMapHeight := float64(tm.PixelHeight)
-	tilesX := int((screenWidth+tileMapWidth)/tileMapWidth) + 2
-	tilesY := int((screenHeight+tileMapHeight)/tileMapHeight) + 2
-	startX := int(cameraX / tileMapWidth)
-	startY := int(cameraY / tileMapHeight)
-	for y := startY - 1; y < startY+tilesY; y++ {
-		for x := startX - 1; x < startX+tilesX; x++ {
-			op := &ebiten.DrawImageOptions{}
-			offsetX := float64(x)*tileMapWidth - cameraX
-			offsetY := float64(y)*tileMapHeight - cameraY
-			op.GeoM.Translate(offsetX, offsetY)
-			screen.DrawImage(tm.Image, op)
-		}
-	}
+	return tileImages
 }
 
 func (tm *TileMap) GetBounds() (x, y, width, height float64) {
@@ -169,7 +159,7 @@ func (tm *TileMap) GetTileAt(worldX, worldY float64) uint32 {
 		return 0
 	}
 	for _, layer := range tm.Map.Layers {
-		if len(layer.Tiles) > 0 {
+		if len(layer.Tiles) > 0 && !isForegroundLayer(layer) {
 			tileIndex := tileY*tm.MapWidth + tileX
 			if tileIndex < len(layer.Tiles) {
 				return layer.Tiles[tileIndex].ID
@@ -179,52 +169,83 @@ func (tm *TileMap) GetTileAt(worldX, worldY float64) uint32 {
 	return 0
 }
 
-func (tm *TileMap) createCollisionObjects() {
-	if tm.Map == nil || tm.CollisionSpace == nil {
-		return
+func (tm *TileMap) CheckCollision(x, y, width, height float64) bool {
+	if tm.CollisionSpace == nil {
+		return false
 	}
-	collisionCount := 0
-	totalTiles := 0
-	for _, layer := range tm.Map.Layers {
-		if len(layer.Tiles) > 0 && layer.Visible {
-			for y := 0; y < tm.MapHeight; y++ {
-				for x := 0; x < tm.MapWidth; x++ {
-					tileIndex := y*tm.MapWidth + x
-					if tileIndex >= len(layer.Tiles) {
-						continue
-					}
-					tile := layer.Tiles[tileIndex]
-					totalTiles++
-					if tile.ID == 0 {
-						continue
-					}
-					if IsTileSolid(tile.ID) {
-						rect := resolv.NewRectangle(
-							float64(x*tm.TileWidth),
-							float64(y*tm.TileHeight),
-							float64(tm.TileWidth),
-							float64(tm.TileHeight))
-						tm.CollisionSpace.Add(rect)
-						collisionCount++
-					}
-				}
-			}
+	checkRect := resolv.NewRectangle(x, y, width, height)
+	for _, shape := range tm.shapesNear(checkRect) {
+		if isWall(shape) && overlaps(checkRect, shape) {
+			return true
 		}
 	}
-	log.Printf("Created collision objects for tilemap: %d collision objects from %d total tiles", collisionCount, totalTiles)
+	return false
 }
 
-func (tm *TileMap) CheckCollision(x, y, width, height float64) bool {
+// CheckLanding reports the surface a box moving down from fromY to toY comes
+// to rest on. Unlike CheckCollision it includes one-way platforms, as long as
+// the box started above them.
+func (tm *TileMap) CheckLanding(x, fromY, toY, width, height float64) (TileCollisionInfo, bool) {
 	if tm.CollisionSpace == nil {
-		return false
+		return TileCollisionInfo{}, false
 	}
+	fromBottom := resolv.NewRectangle(x, fromY, width, height).Bounds().Max.Y
+	checkRect := resolv.NewRectangle(x, toY, width, height)
+	for _, shape := range tm.shapesNear(checkRect) {
+		if canLandOn(shape, fromBottom) && overlaps(checkRect, shape) {
+			return ShapeInfo(shape), true
+		}
+	}
+	return TileCollisionInfo{}, false
+}
+
+// TileCollisionsAt returns the info of every shape overlapping the box,
+// including hazards that do not block movement.
+func (tm *TileMap) TileCollisionsAt(x, y, width, height float64) []TileCollisionInfo {
+	if tm.CollisionSpace == nil {
+		return nil
+	}
+	var infos []TileCollisionInfo
 	checkRect := resolv.NewRectangle(x, y, width, height)
-	for _, shape := range tm.CollisionSpace.Shapes() {
-		if intersections := checkRect.Intersection(shape); len(intersections.Intersections) > 0 {
-			return true
+	for _, shape := range tm.shapesNear(checkRect) {
+		if overlaps(checkRect, shape) {
+			infos = append(infos, ShapeInfo(shape))
 		}
 	}
-	return false
+	return infos
+}
+
+func overlaps(rect *resolv.ConvexPolygon, shape resolv.IShape) bool {
+	return len(rect.Intersection(shape).Intersections) > 0
+}
+
+// isWall reports whether shape blocks movement from every side.
+func isWall(shape resolv.IShape) bool {
+	tags := *shape.Tags()
+	return tags.Has(TagSolid) && !tags.Has(TagOneWay)
+}
+
+// canLandOn reports whether a box whose bottom was at fromBottom is stopped by
+// shape when moving down.
+func canLandOn(shape resolv.IShape, fromBottom float64) bool {
+	tags := *shape.Tags()
+	if !tags.Has(TagSolid) {
+		return false
+	}
+	return !tags.Has(TagOneWay) || fromBottom <= shape.Bounds().Min.Y+oneWayTolerance
+}
+
+// shapesNear returns the collision shapes registered in the space cells that
+// rect overlaps. The space walks its cells row by row, so the order only
+// depends on the map and needs no sorting to stay deterministic. The slice is
+// reused by the next call and must not be kept.
+func (tm *TileMap) shapesNear(rect *resolv.ConvexPolygon) []resolv.IShape {
+	tm.nearShapes = tm.nearShapes[:0]
+	tm.CollisionSpace.FilterCells(rect.Bounds()).FilterShapes().ForEach(func(shape resolv.IShape) bool {
+		tm.nearShapes = append(tm.nearShapes, shape)
+		return true
+	})
+	return tm.nearShapes
 }
 
 func (tm *TileMap) checkTiledCollision(x, y, width, height float64) bool {
@@ -238,11 +259,8 @@ func (tm *TileMap) checkTiledCollision(x, y, width, height float64) bool {
 		for tileX := leftTileX; tileX <= rightTileX; tileX++ {
 			localX := x - float64(tileX)*tileMapWidth
 			localY := y - float64(tileY)*tileMapHeight
-			checkRect := resolv.NewRectangle(localX, localY, width, height)
-			for _, shape := range tm.CollisionSpace.Shapes() {
-				if intersections := checkRect.Intersection(shape); len(intersections.Intersections) > 0 {
-					return true
-				}
+			if tm.CheckCollision(localX, localY, width, height) {
+				return true
 			}
 		}
 	}
@@ -250,14 +268,8 @@ func (tm *TileMap) checkTiledCollision(x, y, width, height float64) bool {
 }
 
 func (tm *TileMap) CheckMovement(fromX, fromY, toX, toY, width, height float64) (float64, float64, bool) {
-	if tm.CollisionSpace == nil {
-		return toX, toY, true
-	}
-	targetRect := resolv.NewRectangle(toX, toY, width, height)
-	for _, shape := range tm.CollisionSpace.Shapes() {
-		if intersections := targetRect.Intersection(shape); len(intersections.Intersections) > 0 {
-			return fromX, fromY, false
-		}
+	if tm.CheckCollision(toX, toY, width, height) {
+		return fromX, fromY, false
 	}
 	return toX, toY, true
 }
@@ -272,6 +284,8 @@ type CollisionResult struct {
 	NormalY      float64
 }
 
+// CheckMovementAdvanced moves a box horizontally and then vertically with
+// SweepBox, stopping each axis at the first shape in the way.
 func (tm *TileMap) CheckMovementAdvanced(fromX, fromY, toX, toY, width, height float64) CollisionResult {
 	result := CollisionResult{
 		HasCollision: false,
@@ -283,52 +297,21 @@ func (tm *TileMap) CheckMovementAdvanced(fromX, fromY, toX, toY, width, height f
 	if tm.CollisionSpace == nil {
 		return result
 	}
-	horizontalRect := resolv.NewRectangle(toX, fromY, width, height)
-	hasHorizontalCollision := false
-	for _, shape := range tm.CollisionSpace.Shapes() {
-		if intersections := horizontalRect.Intersection(shape); len(intersections.Intersections) > 0 {
-			hasHorizontalCollision = true
-			result.HasCollision = true
-			result.CollisionX = true
-			break
-		}
-	}
-	verticalRect := resolv.NewRectangle(fromX, toY, width, height)
-	hasVerticalCollision := false
-	for _, shape := range tm.CollisionSpace.Shapes() {
-		if intersections := verticalRect.Intersection(shape); len(intersections.Intersections) > 0 {
-			hasVerticalCollision = true
-			result.HasCollision = true
-			result.CollisionY = true
-			break
-		}
-	}
-	if hasHorizontalCollision {
-		result.AdjustedX = fromX
-	} else {
-		result.AdjustedX = toX
+
+	horizontal := tm.SweepBox(fromX, fromY, width, height, toX-fromX, 0)
+	result.AdjustedX = horizontal.X
+	if horizontal.Hit {
+		result.HasCollision = true
+		result.CollisionX = true
+		result.NormalX = horizontal.NormalX
 	}
-	if hasVerticalCollision {
-		if toY > fromY {
-			bestY := toY
-			targetRect := resolv.NewRectangle(fromX, toY, width, height)
-			for _, shape := range tm.CollisionSpace.Shapes() {
-				if intersections := targetRect.Intersection(shape); len(intersections.Intersections) > 0 {
-					if rect, ok := shape.(*resolv.ConvexPolygon); ok {
-						shapePos := rect.Position()
-						tileTop := shapePos.Y - height
-						if tileTop < bestY {
-							bestY = tileTop
-						}
-					}
-				}
-			}
-			result.AdjustedY = bestY
-		} else {
-			result.AdjustedY = fromY
-		}
-	} else {
-		result.AdjustedY = toY
+
+	vertical := tm.SweepBox(result.AdjustedX, fromY, width, height, 0, toY-fromY)
+	result.AdjustedY = vertical.Y
+	if vertical.Hit {
+		result.HasCollision = true
+		result.CollisionY = true
+		result.NormalY = vertical.NormalY
 	}
 	return result
 }
@@ -337,12 +320,12 @@ func (tm *TileMap) CheckGroundCollision(x, y, width, height float64) (bool, floa
 	if tm.CollisionSpace == nil {
 		return false, y
 	}
+	fromBottom := resolv.NewRectangle(x, y, width, height).Bounds().Max.Y
 	checkRect := resolv.NewRectangle(x, y+1, width, height)
-	for _, shape := range tm.CollisionSpace.Shapes() {
-		if intersections := checkRect.Intersection(shape); len(intersections.Intersections) > 0 {
+	for _, shape := range tm.shapesNear(checkRect) {
+		if canLandOn(shape, fromBottom) && overlaps(checkRect, shape) {
 			if rect, ok := shape.(*resolv.ConvexPolygon); ok {
-				shapePos := rect.Position()
-				return true, shapePos.Y - height
+				return true, tm.shapeSurfaceY(rect) - height
 			}
 		}
 	}
@@ -362,11 +345,28 @@ func (tm *TileMap) GetCollisionShapes() []*resolv.ConvexPolygon {
 	return shapes
 }
 
+// IsTileSolid is the fallback for tiles without a solid property: every tile
+// but the empty first one blocks.
 func IsTileSolid(tileID uint32) bool {
 	return tileID > 0
 }
 
-func (tm *TileMap) GetTileCollisionInfo(worldX, worldY float64) (uint32, bool) {
-	tileID := tm.GetTileAt(worldX, worldY)
-	return tileID, IsTileSolid(tileID)
+// GetTileCollisionInfo returns the collision info of the tile drawn at a world
+// position.
+func (tm *TileMap) GetTileCollisionInfo(worldX, worldY float64) TileCollisionInfo {
+	if tm.Map == nil || worldX < 0 || worldY < 0 {
+		return TileCollisionInfo{Friction: 1}
+	}
+	tileX := int(worldX) / tm.TileWidth
+	tileY := int(worldY) / tm.TileHeight
+	if tileX >= tm.MapWidth || tileY >= tm.MapHeight {
+		return TileCollisionInfo{Friction: 1}
+	}
+	for _, layer := range tm.Map.Layers {
+		tileIndex := tileY*tm.MapWidth + tileX
+		if tileIndex < len(layer.Tiles) && !isForegroundLayer(layer) {
+			return tm.layerTileInfo(layer.Tiles[tileIndex])
+		}
+	}
+	return TileCollisionInfo{Friction: 1}
 }
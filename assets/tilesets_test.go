@@ -0,0 +1,100 @@
+package assets
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lafriks/go-tiled"
+	"github.com/solarlune/resolv"
+)
+
+const testTileSize = 32
+
+// newTestTileMap builds a single-layer map of 32px tiles from rows of text:
+// '#' is a solid tile, '=' a one-way platform and anything else is empty.
+func newTestTileMap(rows ...string) *TileMap {
+	tileset := &tiled.Tileset{
+		FirstGID: 1,
+		Tiles: []*tiled.TilesetTile{{
+			ID:         2,
+			Properties: tiled.Properties{{Name: "oneway", Type: "bool", Value: "true"}},
+		}},
+	}
+
+	width, height := len(rows[0]), len(rows)
+	layer := &tiled.Layer{Name: "Tiles", Visible: true}
+	for _, row := range rows {
+		for _, cell := range row {
+			tile := &tiled.LayerTile{Tileset: tileset}
+			switch cell {
+			case '#':
+				tile.ID = 1
+			case '=':
+				tile.ID = 2
+			default:
+				tile.Nil = true
+			}
+			layer.Tiles = append(layer.Tiles, tile)
+		}
+	}
+
+	gameMap := &tiled.Map{
+		Width:      width,
+		Height:     height,
+		TileWidth:  testTileSize,
+		TileHeight: testTileSize,
+		Tilesets:   []*tiled.Tileset{tileset},
+		Layers:     []*tiled.Layer{layer},
+	}
+	tm := &TileMap{
+		Map:            gameMap,
+		TileWidth:      testTileSize,
+		TileHeight:     testTileSize,
+		MapWidth:       width,
+		MapHeight:      height,
+		PixelWidth:     width * testTileSize,
+		PixelHeight:    height * testTileSize,
+		CollisionSpace: resolv.NewSpace(width*testTileSize, height*testTileSize, testTileSize, testTileSize),
+		tileInfo:       parseTileInfo(gameMap),
+		tileOutlines:   parseTileOutlines(gameMap),
+	}
+	tm.createCollisionObjects()
+	return tm
+}
+
+// benchmarkRows lays out a level like the shipped ones: scattered one-way
+// platforms over a solid floor, with a pit every 40 tiles.
+func benchmarkRows(width int) []string {
+	rows := make([]string, 20)
+	for y := range rows {
+		var row strings.Builder
+		for x := 0; x < width; x++ {
+			switch {
+			case y >= 12 && x%40 < 36:
+				row.WriteByte('#')
+			case (y == 8 && x%17 < 3) || (y == 4 && x%23 < 3):
+				row.WriteByte('=')
+			default:
+				row.WriteByte('.')
+			}
+		}
+		rows[y] = row.String()
+	}
+	return rows
+}
+
+func BenchmarkCheckCollision(b *testing.B) {
+	for _, width := range []int{100, 500, 2000, 10000} {
+		b.Run(fmt.Sprintf("width=%d", width), func(b *testing.B) {
+			tm := newTestTileMap(benchmarkRows(width)...)
+			span := float64(tm.PixelWidth - 2*testTileSize)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				x := testTileSize + float64(i*37%int(span))
+				tm.CheckCollision(x, 370, 24, 40)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -9,7 +12,67 @@ import (
 )
 
 func main() {
-	g := src.NewGame()
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for the run")
+	recordPath := flag.String("record", "", "record the session's input to a replay file")
+	replayPath := flag.String("replay", "", "play back a replay file and verify its final state")
+	physicsPath := flag.String("physics", "", "load player physics from a JSON profile instead of the built-in one")
+	retentionName := flag.String("checkpoints", src.DefaultCheckpointRetention.String(), "world state kept on respawn: snapshot, keep-items or reset-world")
+	flag.Parse()
+
+	if *recordPath != "" && *replayPath != "" {
+		fmt.Println("-record and -replay can't be used together")
+		os.Exit(1)
+	}
+
+	retention, err := src.ParseCheckpointRetention(*retentionName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var replay *src.Replay
+	if *replayPath != "" {
+		replay, err = src.LoadReplay(*replayPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		*seed = replay.Seed
+	}
+
+	fmt.Printf("Seed: %d\n", *seed)
+	g := src.NewGame(*seed)
+	g.SetCheckpointRetention(retention)
+
+	if *physicsPath != "" {
+		profile, err := src.LoadPhysicsProfile(*physicsPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		g.SetPhysicsProfile(profile)
+	}
+
+	if *recordPath != "" || *replayPath != "" {
+		g.SetSavePath("")
+	}
+
+	var replaySource *src.ReplaySource
+	if replay != nil {
+		if err := replay.CheckPhysics(g); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		replaySource = src.NewReplaySource(replay)
+		g.SetClock(src.NewFixedClock(replay.TickRate))
+		g.SetInputSource(replaySource)
+	}
+
+	var recorder *src.ReplayRecorder
+	if *recordPath != "" {
+		recorder = src.NewReplayRecorder(src.NewDefaultInputSource(), *seed, src.DefaultTickRate)
+		g.SetInputSource(recorder)
+	}
 
 	ebiten.SetWindowSize(1280, 720)
 	ebiten.SetWindowTitle("FIGHT FOR UNION")
@@ -24,11 +87,27 @@ func main() {
 		game:           g,
 		windowedWidth:  1280,
 		windowedHeight: 720,
+		replaySource:   replaySource,
 	}
 
 	if err := ebiten.RunGame(gameWrapper); err != nil {
 		panic(err)
 	}
+
+	if recorder != nil {
+		if err := recorder.Finish(g).Save(*recordPath); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Replay saved to %s\n", *recordPath)
+	}
+
+	if replay != nil {
+		if err := replay.Verify(g); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("Replay matches recording")
+	}
 }
 
 type GameWrapper struct {
@@ -36,6 +115,7 @@ type GameWrapper struct {
 	windowedWidth  int
 	windowedHeight int
 	isFullscreen   bool
+	replaySource   *src.ReplaySource
 }
 
 func (gw *GameWrapper) Update() error {
@@ -47,6 +127,10 @@ func (gw *GameWrapper) Update() error {
 		gw.toggleFullscreen()
 	}
 
+	if gw.replaySource != nil && gw.replaySource.Done() {
+		return ebiten.Termination
+	}
+
 	return gw.game.Update()
 }
 
@@ -0,0 +1,227 @@
+package src
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/ebijam25/assets"
+)
+
+const (
+	CheckpointObjectType  = "checkpoint"
+	checkpointSpawnMargin = 32.0
+)
+
+// CheckpointRetention decides which world state survives a respawn at a
+// checkpoint.
+type CheckpointRetention int
+
+const (
+	// CheckpointRestoreSnapshot rewinds items and madness to the moment the
+	// checkpoint was activated.
+	CheckpointRestoreSnapshot CheckpointRetention = iota
+	// CheckpointKeepItems keeps everything collected since, but rewinds
+	// madness.
+	CheckpointKeepItems
+	// CheckpointResetWorld keeps only the position; items and madness start
+	// over.
+	CheckpointResetWorld
+)
+
+const DefaultCheckpointRetention = CheckpointRestoreSnapshot
+
+var checkpointRetentionNames = map[CheckpointRetention]string{
+	CheckpointRestoreSnapshot: "snapshot",
+	CheckpointKeepItems:       "keep-items",
+	CheckpointResetWorld:      "reset-world",
+}
+
+func (r CheckpointRetention) String() string {
+	if name, ok := checkpointRetentionNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("CheckpointRetention(%d)", int(r))
+}
+
+// ParseCheckpointRetention reads a retention rule by the name String gives
+// it: "snapshot", "keep-items" or "reset-world".
+func ParseCheckpointRetention(name string) (CheckpointRetention, error) {
+	for retention, retentionName := range checkpointRetentionNames {
+		if retentionName == name {
+			return retention, nil
+		}
+	}
+	return DefaultCheckpointRetention, fmt.Errorf("checkpoint: unknown retention %q", name)
+}
+
+type Checkpoint struct {
+	ID            uint32
+	Name          string
+	X, Y          float64
+	Width, Height float64
+	Active        bool
+	PulsePhase    float64
+	FlashTimer    float64
+
+	snapshot *SaveData
+}
+
+func LoadCheckpoints(tileMap *assets.TileMap) []*Checkpoint {
+	var checkpoints []*Checkpoint
+	for _, object := range tileMap.ObjectsOfType(CheckpointObjectType) {
+		checkpoints = append(checkpoints, &Checkpoint{
+			ID:     object.ID,
+			Name:   object.Name,
+			X:      object.X,
+			Y:      object.Y,
+			Width:  object.Width,
+			Height: object.Height,
+		})
+	}
+	return checkpoints
+}
+
+func (c *Checkpoint) Update(deltaTime float64) {
+	c.PulsePhase += deltaTime * 3.0
+	if c.FlashTimer > 0 {
+		c.FlashTimer -= deltaTime
+	}
+}
+
+func (c *Checkpoint) Overlaps(x, y, width, height float64) bool {
+	return x < c.X+c.Width && x+width > c.X && y < c.Y+c.Height && y+height > c.Y
+}
+
+// SpawnPosition returns a player position just above the checkpoint's base,
+// centred on it.
+func (c *Checkpoint) SpawnPosition(p *Player) (x, y float64) {
+	x = c.X + c.Width/2 - float64(SpriteWidth)*p.Scale/2
+	y = c.Y + c.Height - float64(HitboxOffsetY+HitboxHeight)*p.Scale - checkpointSpawnMargin
+	return x, y
+}
+
+func (c *Checkpoint) Draw(screen *ebiten.Image, cameraX, cameraY float64) {
+	screenX := float32(c.X - cameraX)
+	screenY := float32(c.Y - cameraY)
+	width := float32(c.Width)
+	height := float32(c.Height)
+
+	poleColor := color.RGBA{90, 80, 110, 255}
+	vector.DrawFilledRect(screen, screenX+width/2-2, screenY, 4, height, poleColor, false)
+
+	flagColor := color.RGBA{120, 110, 140, 200}
+	glowRadius := float32(0)
+	if c.Active {
+		pulse := 0.5 + 0.5*math.Sin(c.PulsePhase)
+		flagColor = color.RGBA{240, 240, 255, 255}
+		glowRadius = float32(12 + 6*pulse)
+		if c.FlashTimer > 0 {
+			glowRadius += float32(c.FlashTimer * 40)
+		}
+	}
+
+	if glowRadius > 0 {
+		vector.DrawFilledCircle(screen, screenX+width/2, screenY+8, glowRadius, color.RGBA{200, 200, 255, 60}, false)
+	}
+	vector.DrawFilledRect(screen, screenX+width/2+2, screenY, width/2, 14, flagColor, false)
+}
+
+func (g *Game) SetCheckpointRetention(retention CheckpointRetention) {
+	g.checkpointRetention = retention
+}
+
+func (g *Game) updateCheckpoints(deltaTime float64) {
+	px, py, pw, ph := g.player.GetBounds()
+	for _, checkpoint := range g.checkpoints {
+		checkpoint.Update(deltaTime)
+		if checkpoint != g.activeCheckpoint && checkpoint.Overlaps(px, py, pw, ph) && !g.player.IsPlayerDead() {
+			g.activateCheckpoint(checkpoint)
+		}
+	}
+}
+
+func (g *Game) activateCheckpoint(checkpoint *Checkpoint) {
+	if g.activeCheckpoint != nil {
+		g.activeCheckpoint.Active = false
+	}
+
+	checkpoint.Active = true
+	checkpoint.FlashTimer = 0.6
+	g.activeCheckpoint = checkpoint
+	checkpoint.snapshot = g.Snapshot()
+
+	x, y := checkpoint.X+checkpoint.Width/2, checkpoint.Y+8
+	g.globalParticleSystem.SpawnBurst(x, y, ParticleTypeHealingLight, 10)
+	g.globalParticleSystem.SpawnBurst(x, y, ParticleTypeStabilityWave, 4)
+
+	g.currentGlitchMessage = "CHECKPOINT REACHED"
+	g.messageTimer = 2.0
+}
+
+func (g *Game) resetCheckpoints() {
+	for _, checkpoint := range g.checkpoints {
+		checkpoint.Active = false
+		checkpoint.snapshot = nil
+	}
+	g.activeCheckpoint = nil
+}
+
+func (g *Game) findCheckpoint(id uint32) *Checkpoint {
+	for _, checkpoint := range g.checkpoints {
+		if checkpoint.ID == id {
+			return checkpoint
+		}
+	}
+	return nil
+}
+
+// respawn returns the player to the last checkpoint, or restarts the run
+// when none was reached.
+func (g *Game) respawn() {
+	checkpoint := g.activeCheckpoint
+	if checkpoint == nil || checkpoint.snapshot == nil {
+		g.restartGame()
+		return
+	}
+
+	snapshot := checkpoint.snapshot
+
+	var err error
+	switch g.checkpointRetention {
+	case CheckpointKeepItems:
+		err = g.ApplySave(g.Snapshot())
+		g.madnessLevel = snapshot.MadnessLevel
+	case CheckpointResetWorld:
+		g.restartGame()
+	default:
+		err = g.ApplySave(snapshot)
+	}
+	if err != nil {
+		log.Printf("Failed to restore checkpoint %d: %v", checkpoint.ID, err)
+		g.restartGame()
+		return
+	}
+
+	g.activeCheckpoint = checkpoint
+	checkpoint.Active = true
+	checkpoint.snapshot = snapshot
+
+	p := g.player
+	p.X, p.Y = checkpoint.SpawnPosition(p)
+	p.VelocityX = 0
+	p.VelocityY = 0
+	p.OnGround = false
+	p.Health = p.MaxHealth
+	p.IsDead = false
+	p.InvulnTimer = 0
+	p.ResetAbilities()
+
+	if p.Camera != nil {
+		p.Camera.X = p.X - p.Camera.ViewportW/2
+		p.Camera.TargetX = p.Camera.X
+	}
+}
@@ -0,0 +1,50 @@
+package src
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const (
+	DefaultTickRate = 60
+	MaxDeltaTime    = 1.0 / 20.0
+)
+
+// Clock supplies the simulation time step for each tick.
+type Clock interface {
+	Tick() float64
+}
+
+type FixedClock struct {
+	Step float64
+}
+
+func NewFixedClock(tickRate int) *FixedClock {
+	if tickRate <= 0 {
+		tickRate = DefaultTickRate
+	}
+	return &FixedClock{Step: 1.0 / float64(tickRate)}
+}
+
+func (c *FixedClock) Tick() float64 {
+	return c.Step
+}
+
+// EbitenClock derives the step from ebiten's measured TPS, so runs using it
+// are not reproducible.
+type EbitenClock struct{}
+
+func (c *EbitenClock) Tick() float64 {
+	deltaTime := 1.0 / float64(DefaultTickRate)
+	if ebiten.ActualTPS() > 0 {
+		deltaTime = 1.0 / ebiten.ActualTPS()
+	}
+	return deltaTime
+}
+
+func clampDeltaTime(deltaTime float64) float64 {
+	if deltaTime > MaxDeltaTime {
+		return MaxDeltaTime
+	}
+	if deltaTime < 0 {
+		return 0
+	}
+	return deltaTime
+}
@@ -1,11 +1,18 @@
 package src
 
 import (
+	"math"
+
 	"github.com/temidaradev/ebijam25/assets"
 )
 
 type CollisionSystem struct {
-	TileMap *assets.TileMap
+	TileMap   *assets.TileMap
+	Platforms []*MovingPlatform
+
+	// pushing is the platform moving a box out of its way, which must not
+	// block that move itself.
+	pushing *MovingPlatform
 }
 
 func NewCollisionSystem(tileMap *assets.TileMap) *CollisionSystem {
@@ -21,6 +28,18 @@ type CollisionBox struct {
 	Height float64
 }
 
+// platformBox converts a box from the convention of the tile queries, which
+// measure it from its centre among shapes that sit half a tile up and left
+// of the drawn tiles, to the drawn top-left rectangle platforms use.
+func (cs *CollisionSystem) platformBox(box CollisionBox) CollisionBox {
+	x, y := box.X-box.Width/2, box.Y-box.Height/2
+	if cs.TileMap != nil {
+		x += float64(cs.TileMap.TileWidth) / 2
+		y += float64(cs.TileMap.TileHeight) / 2
+	}
+	return CollisionBox{X: x, Y: y, Width: box.Width, Height: box.Height}
+}
+
 type CollisionInfo struct {
 	HasCollision bool
 	NewX         float64
@@ -56,15 +75,193 @@ func (cs *CollisionSystem) CheckMovement(from, to CollisionBox) CollisionInfo {
 	return info
 }
 
+// SweepResult is the outcome of moving a box with Sweep.
+type SweepResult struct {
+	Hit bool
+	// Time is the completed fraction of the move, from 0 to 1.
+	Time             float64
+	NormalX, NormalY float64
+	// X and Y are where the box comes to rest, just short of the contact.
+	X, Y     float64
+	Surface  assets.TileCollisionInfo
+	Platform *MovingPlatform
+}
+
+// Sweep moves box by dx, dy and stops it at the first tile or platform in
+// the way, however far it travels in one step.
+func (cs *CollisionSystem) Sweep(box CollisionBox, dx, dy float64) SweepResult {
+	result := SweepResult{Time: 1, X: box.X + dx, Y: box.Y + dy}
+	if cs.TileMap != nil {
+		hit := cs.TileMap.SweepBox(box.X, box.Y, box.Width, box.Height, dx, dy)
+		if hit.Hit {
+			result = SweepResult{
+				Hit:     true,
+				Time:    hit.Time,
+				NormalX: hit.NormalX,
+				NormalY: hit.NormalY,
+				Surface: hit.Info,
+			}
+		}
+	}
+
+	platformBox := cs.platformBox(box)
+	for _, platform := range cs.Platforms {
+		if platform == cs.pushing {
+			continue
+		}
+		t, nx, ny, ok := assets.SweepAABB(platformBox.X, platformBox.Y, box.Width, box.Height, dx, dy,
+			platform.X, platform.Y, platform.Width, platform.Height)
+		if ok && (!result.Hit || t < result.Time) {
+			result = SweepResult{
+				Hit:      true,
+				Time:     t,
+				NormalX:  nx,
+				NormalY:  ny,
+				Surface:  assets.TileCollisionInfo{Solid: true, Friction: 1},
+				Platform: platform,
+			}
+		}
+	}
+
+	if result.Hit {
+		result.X = box.X + dx*result.Time + result.NormalX*assets.SweepSkin
+		result.Y = box.Y + dy*result.Time + result.NormalY*assets.SweepSkin
+	}
+	return result
+}
+
 func (cs *CollisionSystem) CheckCollisionAtPoint(box CollisionBox) bool {
+	if cs.PlatformAt(box) != nil {
+		return true
+	}
 	if cs.TileMap == nil {
 		return false
 	}
 	return cs.TileMap.CheckCollision(box.X, box.Y, box.Width, box.Height)
 }
 
-func (cs *CollisionSystem) IsOnGround(box CollisionBox) bool {
+// Landing returns the surface a box moving down from from to to would land
+// on. One-way platforms count here, provided the box started above them.
+func (cs *CollisionSystem) Landing(from, to CollisionBox) (assets.TileCollisionInfo, bool) {
+	fromBox := cs.platformBox(from)
+	if platform := cs.PlatformAt(to); platform != nil && fromBox.Y+fromBox.Height <= platform.Y+platformRideTolerance {
+		return assets.TileCollisionInfo{Solid: true, Friction: 1}, true
+	}
+	if cs.TileMap == nil {
+		return assets.TileCollisionInfo{}, false
+	}
+	return cs.TileMap.CheckLanding(to.X, from.Y, to.Y, to.Width, to.Height)
+}
+
+// PlatformAt returns the first moving platform overlapping box, if any.
+func (cs *CollisionSystem) PlatformAt(box CollisionBox) *MovingPlatform {
+	platformBox := cs.platformBox(box)
+	for _, platform := range cs.Platforms {
+		if platform.Overlaps(platformBox) {
+			return platform
+		}
+	}
+	return nil
+}
+
+// TileCollisions returns the collision info of every tile shape touching box,
+// including non-blocking hazards.
+func (cs *CollisionSystem) TileCollisions(box CollisionBox) []assets.TileCollisionInfo {
 	if cs.TileMap == nil {
+		return nil
+	}
+	return cs.TileMap.TileCollisionsAt(box.X, box.Y, box.Width, box.Height)
+}
+
+// StepUp returns the smallest lift, up to maxStep, that frees a box pushed
+// into a slope. Boxes blocked by anything but a slope are never lifted.
+func (cs *CollisionSystem) StepUp(box CollisionBox, maxStep float64) (float64, bool) {
+	if cs.TileMap == nil || !cs.TileMap.SlopeAt(box.X, box.Y, box.Width, box.Height) {
+		return 0, false
+	}
+	for lift := 1.0; lift <= maxStep; lift++ {
+		lifted := box
+		lifted.Y -= lift
+		if !cs.CheckCollisionAtPoint(lifted) {
+			return lift, true
+		}
+	}
+	return 0, false
+}
+
+// SnapToGround returns how far a box can drop, up to maxDistance, before it
+// rests on ground. It keeps walkers glued to a slope on the way down.
+func (cs *CollisionSystem) SnapToGround(box CollisionBox, maxDistance float64) (float64, bool) {
+	for drop := 0.0; drop <= maxDistance; drop++ {
+		below := box
+		below.Y += drop + 1
+		if _, ok := cs.Landing(box, below); ok {
+			return drop, true
+		}
+	}
+	return 0, false
+}
+
+// Ledge is a tile corner a box can hang from.
+type Ledge struct {
+	// Dir is 1 when the wall is to the right of the box and -1 when it is to
+	// the left.
+	Dir float64
+	// Hang is the box hanging with its top level with the corner, and Stand
+	// the box standing on the ledge after climbing up.
+	Hang  CollisionBox
+	Stand CollisionBox
+}
+
+const (
+	ledgeProbeWidth = 2.0
+	ledgeGrabMargin = 2.0
+)
+
+// FindLedge looks for a tile or platform corner on the dir side of a box whose top edge
+// passed it while moving from from to to. The wall below the corner has to
+// touch the box, and there has to be room for the box to hang beside it and
+// to stand on top of it.
+func (cs *CollisionSystem) FindLedge(from, to CollisionBox, dir float64) (Ledge, bool) {
+	if dir == 0 {
+		return Ledge{}, false
+	}
+
+	// Tile queries measure a box from its center, so the top edge and the
+	// probe beside the box are placed around it.
+	halfW, halfH := to.Width/2, to.Height/2
+	probeX := to.X + dir*(halfW+ledgeProbeWidth/2)
+	blocked := func(y float64) bool {
+		return cs.CheckCollisionAtPoint(CollisionBox{X: probeX, Y: y + 0.5, Width: ledgeProbeWidth, Height: 1})
+	}
+
+	top := math.Floor(math.Min(from.Y, to.Y)-halfH) - ledgeGrabMargin
+	bottom := math.Max(from.Y, to.Y) - halfH + ledgeGrabMargin
+	free := !blocked(top - 1)
+	for y := top; y <= bottom; y++ {
+		if !blocked(y) {
+			free = true
+			continue
+		}
+		if !free {
+			continue
+		}
+
+		ledge := Ledge{
+			Dir:   dir,
+			Hang:  CollisionBox{X: to.X, Y: y + halfH, Width: to.Width, Height: to.Height},
+			Stand: CollisionBox{X: to.X + dir*to.Width, Y: y - halfH - assets.SweepSkin, Width: to.Width, Height: to.Height},
+		}
+		if cs.CheckCollisionAtPoint(ledge.Hang) || cs.CheckCollisionAtPoint(ledge.Stand) {
+			return Ledge{}, false
+		}
+		return ledge, true
+	}
+	return Ledge{}, false
+}
+
+func (cs *CollisionSystem) IsOnGround(box CollisionBox) bool {
+	if cs.TileMap == nil && len(cs.Platforms) == 0 {
 		return false
 	}
 
@@ -86,7 +283,11 @@ func (cs *CollisionSystem) IsOnGround(box CollisionBox) bool {
 			return true
 		}
 	}
-	return false
+
+	below := box
+	below.Y += 3
+	_, onPlatform := cs.Landing(box, below)
+	return onPlatform
 }
 
 func (cs *CollisionSystem) GetSafePosition(box CollisionBox) (float64, float64, bool) {
@@ -165,15 +366,23 @@ type GameObject interface {
 	OnCollision(info CollisionInfo)
 }
 
+// UpdateGameObject moves obj by deltaX, deltaY, sweeping each axis in turn
+// so it slides along whatever it hits.
 func (cs *CollisionSystem) UpdateGameObject(obj GameObject, deltaX, deltaY float64) {
-	currentBox := obj.GetCollisionBox()
-	targetBox := CollisionBox{
-		X:      currentBox.X + deltaX,
-		Y:      currentBox.Y + deltaY,
-		Width:  currentBox.Width,
-		Height: currentBox.Height,
-	}
-	info := cs.SlideMovement(currentBox, targetBox)
+	box := obj.GetCollisionBox()
+
+	horizontal := cs.Sweep(box, deltaX, 0)
+	box.X = horizontal.X
+	vertical := cs.Sweep(box, 0, deltaY)
+
+	info := CollisionInfo{
+		HasCollision: horizontal.Hit || vertical.Hit,
+		NewX:         horizontal.X,
+		NewY:         vertical.Y,
+		HitWall:      horizontal.Hit,
+		HitGround:    vertical.Hit && vertical.NormalY < 0,
+		HitCeiling:   vertical.Hit && vertical.NormalY > 0,
+	}
 	obj.SetPosition(info.NewX, info.NewY)
 	if info.HasCollision {
 		obj.OnCollision(info)
@@ -137,6 +137,58 @@ func (c *ControllerInput) IsRightPressed() bool {
 	return x > c.config.DeadZoneLeft
 }
 
+// IsUpPressed reports the d-pad or a stick pushed up. The stick only counts
+// within the up, up-left and up-right eighths, so a mostly sideways push is
+// not read as a diagonal.
+func (c *ControllerInput) IsUpPressed() bool {
+	if !c.isActive {
+		return false
+	}
+
+	if c.hasStandardLayout {
+		if ebiten.IsStandardGamepadButtonPressed(c.gamepadID, ebiten.StandardGamepadButtonLeftTop) {
+			return true
+		}
+	} else {
+		if ebiten.IsGamepadButtonPressed(c.gamepadID, 12) {
+			return true
+		}
+	}
+
+	return c.stickVertical() < 0
+}
+
+func (c *ControllerInput) IsDownPressed() bool {
+	if !c.isActive {
+		return false
+	}
+
+	if c.hasStandardLayout {
+		if ebiten.IsStandardGamepadButtonPressed(c.gamepadID, ebiten.StandardGamepadButtonLeftBottom) {
+			return true
+		}
+	} else {
+		if ebiten.IsGamepadButtonPressed(c.gamepadID, 13) {
+			return true
+		}
+	}
+
+	return c.stickVertical() > 0
+}
+
+// stickVertical returns -1 or 1 when the left stick points into one of the
+// three upper or lower eighths, and 0 otherwise.
+func (c *ControllerInput) stickVertical() int {
+	x, y := c.GetLeftStick()
+	if math.Abs(y) <= c.config.DeadZoneLeft || math.Abs(y) < math.Abs(x)*math.Tan(math.Pi/8) {
+		return 0
+	}
+	if y < 0 {
+		return -1
+	}
+	return 1
+}
+
 func (c *ControllerInput) IsUpJustPressed() bool {
 	if !c.isActive {
 		return false
@@ -232,6 +284,18 @@ func (c *ControllerInput) IsRollJustPressed() bool {
 	return inpututil.IsGamepadButtonJustPressed(c.gamepadID, 0)
 }
 
+func (c *ControllerInput) IsDashJustPressed() bool {
+	if !c.isActive {
+		return false
+	}
+	if c.hasStandardLayout {
+		return inpututil.IsStandardGamepadButtonJustPressed(c.gamepadID, ebiten.StandardGamepadButtonRightLeft) ||
+			inpututil.IsStandardGamepadButtonJustPressed(c.gamepadID, ebiten.StandardGamepadButtonFrontTopLeft)
+	}
+	return inpututil.IsGamepadButtonJustPressed(c.gamepadID, 2) ||
+		inpututil.IsGamepadButtonJustPressed(c.gamepadID, 4)
+}
+
 func (c *ControllerInput) IsAttackJustPressed() bool {
 	if !c.isActive {
 		return false
@@ -3,7 +3,6 @@ package src
 import (
 	"image/color"
 	"math"
-	"math/rand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -55,9 +54,11 @@ type EndingAnimation struct {
 		Layer int
 	}
 	UnionPoints []struct{ X, Y float64 }
+
+	rng *RandomStreams
 }
 
-func NewEndingAnimation(screenWidth, screenHeight int) *EndingAnimation {
+func NewEndingAnimation(screenWidth, screenHeight int, rng *RandomStreams) *EndingAnimation {
 	centerX := float64(screenWidth) / 2
 	centerY := float64(screenHeight) / 2
 
@@ -71,6 +72,7 @@ func NewEndingAnimation(screenWidth, screenHeight int) *EndingAnimation {
 		IsActive:        false,
 		GameCloseTimer:  0,
 		MusicFadeVolume: 1.0,
+		rng:             rng,
 	}
 
 	ending.generateCrystalPoints()
@@ -249,8 +251,8 @@ func (ea *EndingAnimation) createFragments(screenWidth, screenHeight int) {
 	groundY := float64(screenHeight) - 50
 
 	for i := 0; i < numFragments; i++ {
-		startX := float64(screenWidth/10) + rand.Float64()*float64(screenWidth*8/10)
-		startY := groundY + rand.Float64()*20
+		startX := float64(screenWidth/10) + ea.rng.Effects.Float64()*float64(screenWidth*8/10)
+		startY := groundY + ea.rng.Effects.Float64()*20
 
 		crystalPoint := ea.CrystalPoints[i]
 		targetX := ea.CrystalCenterX + crystalPoint.X*ea.CrystalScale
@@ -277,10 +279,10 @@ func (ea *EndingAnimation) createFragments(screenWidth, screenHeight int) {
 			TargetY:      targetY,
 			VelocityX:    0,
 			VelocityY:    0,
-			Size:         2 + rand.Float64()*3,
+			Size:         2 + ea.rng.Effects.Float64()*3,
 			Progress:     0,
-			DelayTimer:   rand.Float64() * 2.0,
-			PulsePhase:   rand.Float64() * math.Pi * 2,
+			DelayTimer:   ea.rng.Effects.Float64() * 2.0,
+			PulsePhase:   ea.rng.Effects.Float64() * math.Pi * 2,
 			Alpha:        1.0,
 			Color:        fragColor,
 			CrystalLayer: crystalPoint.Layer,
@@ -293,8 +295,8 @@ func (ea *EndingAnimation) createUnionFragments() {
 	ea.UnionFragments = make([]CrystalFragmentParticle, numUnionFragments)
 
 	for i := 0; i < numUnionFragments; i++ {
-		angle := rand.Float64() * 2 * math.Pi
-		radius := 100 + rand.Float64()*50
+		angle := ea.rng.Effects.Float64() * 2 * math.Pi
+		radius := 100 + ea.rng.Effects.Float64()*50
 		startX := ea.CrystalCenterX + radius*math.Cos(angle)
 		startY := ea.CrystalCenterY + radius*math.Sin(angle)
 
@@ -313,10 +315,10 @@ func (ea *EndingAnimation) createUnionFragments() {
 			TargetY:      targetY,
 			VelocityX:    0,
 			VelocityY:    0,
-			Size:         2 + rand.Float64()*2,
+			Size:         2 + ea.rng.Effects.Float64()*2,
 			Progress:     0,
-			DelayTimer:   rand.Float64() * 1.0,
-			PulsePhase:   rand.Float64() * math.Pi * 2,
+			DelayTimer:   ea.rng.Effects.Float64() * 1.0,
+			PulsePhase:   ea.rng.Effects.Float64() * math.Pi * 2,
 			Alpha:        1.0,
 			Color:        fragColor,
 			CrystalLayer: 0,
@@ -607,8 +609,12 @@ func (ea *EndingAnimation) IsComplete() bool {
 	return ea.State == EndingStateUnionComplete
 }
 
-func (ea *EndingAnimation) ShouldCloseGame() bool {
-	return false
+const endingHoldTime = 4.0
+
+// Finished reports whether the union has formed and been held on screen
+// long enough to move on.
+func (ea *EndingAnimation) Finished() bool {
+	return ea.IsActive && ea.State == EndingStateUnionComplete && ea.Timer > endingHoldTime
 }
 
 func (ea *EndingAnimation) GetMusicVolume() float64 {
@@ -0,0 +1,156 @@
+package src
+
+import "reflect"
+
+// EventBus delivers gameplay events to subscribers synchronously, in the
+// order they subscribed, so handlers stay deterministic under replays.
+type EventBus struct {
+	handlers map[reflect.Type][]eventHandler
+	nextID   int
+}
+
+type eventHandler struct {
+	id int
+	fn any
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[reflect.Type][]eventHandler)}
+}
+
+// Subscribe registers handler for events of type E and returns a function
+// that removes it again.
+func Subscribe[E any](bus *EventBus, handler func(E)) (unsubscribe func()) {
+	eventType := reflect.TypeFor[E]()
+	bus.nextID++
+	id := bus.nextID
+	bus.handlers[eventType] = append(bus.handlers[eventType], eventHandler{id: id, fn: handler})
+
+	return func() {
+		handlers := bus.handlers[eventType]
+		for i, h := range handlers {
+			if h.id == id {
+				bus.handlers[eventType] = append(handlers[:i:i], handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish calls every handler subscribed to E. A nil bus drops the event.
+func Publish[E any](bus *EventBus, event E) {
+	if bus == nil {
+		return
+	}
+	for _, h := range bus.handlers[reflect.TypeFor[E]()] {
+		h.fn.(func(E))(event)
+	}
+}
+
+type ItemHit struct {
+	Item *SpecialItem
+}
+
+type ItemCollected struct {
+	Item *SpecialItem
+}
+
+type PlayerDamaged struct {
+	Amount int
+	Health int
+}
+
+type DeathCause int
+
+const (
+	DeathByDamage DeathCause = iota
+	DeathByMadness
+	DeathByFalling
+)
+
+type PlayerDied struct {
+	Cause DeathCause
+	X, Y  float64
+}
+
+// MadnessThresholdCrossed fires when the madness level passes one of
+// MadnessThresholds in either direction.
+type MadnessThresholdCrossed struct {
+	Threshold float64
+	Level     float64
+	Rising    bool
+}
+
+// UnionReady fires when every item has been collected and the Union Crystal
+// appears.
+type UnionReady struct {
+	Crystal *SpecialItem
+}
+
+var MadnessThresholds = []float64{0.25, 0.5, 0.8, 1.0}
+
+// RunStats tallies the current run from gameplay events.
+type RunStats struct {
+	ItemsHit       int
+	ItemsCollected map[SpecialItemType]int
+	DamageTaken    int
+	Deaths         int
+	MadnessSpikes  int
+}
+
+func NewRunStats(bus *EventBus) *RunStats {
+	stats := &RunStats{ItemsCollected: make(map[SpecialItemType]int)}
+
+	Subscribe(bus, func(ItemHit) { stats.ItemsHit++ })
+	Subscribe(bus, func(e ItemCollected) { stats.ItemsCollected[e.Item.ItemType]++ })
+	Subscribe(bus, func(e PlayerDamaged) { stats.DamageTaken += e.Amount })
+	Subscribe(bus, func(PlayerDied) { stats.Deaths++ })
+	Subscribe(bus, func(e MadnessThresholdCrossed) {
+		if e.Rising {
+			stats.MadnessSpikes++
+		}
+	})
+
+	return stats
+}
+
+// subscribeGameplayEvents wires the game's own reactions to the bus.
+func (g *Game) subscribeGameplayEvents() {
+	Subscribe(g.events, func(e ItemHit) {
+		item := e.Item
+		g.globalParticleSystem.SpawnBurst(item.X+item.Width/2, item.Y+item.Height/2, ParticleTypeHallucinationSpark, 3)
+	})
+
+	Subscribe(g.events, func(e ItemCollected) {
+		item := e.Item
+		g.triggerMadness(item.ItemType)
+		g.updateProgression(item.ItemType)
+		g.spawnCollectionEffect(item.X+item.Width/2, item.Y+item.Height/2, item.ItemType)
+	})
+
+	Subscribe(g.events, func(e UnionReady) {
+		g.currentGlitchMessage = "THE UNION CRYSTAL AWAKENS"
+		g.messageTimer = 4.0
+	})
+}
+
+func (g *Game) checkMadnessThresholds() {
+	previous, current := g.lastMadnessLevel, g.madnessLevel
+	g.lastMadnessLevel = current
+
+	for _, threshold := range MadnessThresholds {
+		if previous < threshold && current >= threshold {
+			Publish(g.events, MadnessThresholdCrossed{Threshold: threshold, Level: current, Rising: true})
+		} else if previous >= threshold && current < threshold {
+			Publish(g.events, MadnessThresholdCrossed{Threshold: threshold, Level: current})
+		}
+	}
+}
+
+func (g *Game) Events() *EventBus {
+	return g.events
+}
+
+func (g *Game) Stats() *RunStats {
+	return g.stats
+}
@@ -0,0 +1,20 @@
+package src
+
+import "testing"
+
+func TestMadnessDeathIsNotDamage(t *testing.T) {
+	g := NewGame(1)
+	g.scenes.Switch(NewPlayScene(g), NoTransition)
+	g.madnessLevel = 1.0
+
+	if err := g.Step(1.0/DefaultTickRate, InputState{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !g.player.IsPlayerDead() {
+		t.Fatal("player survived full madness")
+	}
+	if stats := g.Stats(); stats.DamageTaken != 0 || stats.Deaths != 1 {
+		t.Fatalf("stats after madness death: damage %d, deaths %d; want 0 and 1", stats.DamageTaken, stats.Deaths)
+	}
+}
@@ -3,12 +3,10 @@ package src
 import (
 	"fmt"
 	"image/color"
+	"log"
 	"math"
-	"math/rand"
-	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/temidaradev/ebijam25/assets"
 	"github.com/temidaradev/esset/v2"
@@ -25,16 +23,22 @@ const (
 )
 
 type Game struct {
-	state              GameState
+	scenes             *SceneManager
 	menu               *Menu
 	parallaxOffset     float64
 	player             *Player
 	lastFrameTime      float64
 	currentEnvironment string
-	controller         *ControllerInput
+	levels             *LevelManager
+	tileMap            *assets.TileMap
+	levelStart         *assets.MapObject
+	exitZones          []ExitZone
+	platforms          []*MovingPlatform
+	input              InputSource
 	showCollisionBoxes bool
 
 	specialItems         []*SpecialItem
+	unionCrystalSpawn    *assets.MapObject
 	collectedItems       map[SpecialItemType]bool
 	totalItemsCollected  int
 	maxItems             int
@@ -53,6 +57,7 @@ type Game struct {
 
 	globalParticleSystem  *ParticleSystem
 	madnessParticleSystem *ParticleSystem
+	dashTrailTimer        float64
 	glitchEffectTimer     float64
 	realityTearTimer      float64
 	chaosIntensityLevel   float64
@@ -75,8 +80,23 @@ type Game struct {
 	difficultyModifier   float64
 	proximityDamageTimer float64
 
+	checkpoints         []*Checkpoint
+	activeCheckpoint    *Checkpoint
+	checkpointRetention CheckpointRetention
+
 	endingAnimation *EndingAnimation
 	endingTriggered bool
+
+	clock          Clock
+	simulationTime float64
+	tickCount      int
+
+	rng      *RandomStreams
+	savePath string
+
+	events           *EventBus
+	stats            *RunStats
+	lastMadnessLevel float64
 }
 
 func init() {
@@ -86,57 +106,33 @@ func init() {
 	assets.InitTileMaps()
 }
 
-func NewGame() *Game {
+func NewGame(seed int64) *Game {
 	screenWidth, screenHeight := 1280, 720
 
-	playerStartX := 100.0
-	playerStartY := 100.0
+	rng := NewRandomStreams(seed)
+
+	savePath, err := DefaultSavePath()
+	if err != nil {
+		log.Printf("Saving disabled: %v", err)
+	}
 
-	return &Game{
-		state:              GameStateMenu,
-		menu:               NewMenu(),
-		player:             NewPlayer(playerStartX, playerStartY, float64(screenWidth), float64(screenHeight), 0, assets.DesertTileMap),
+	menu := NewMenu(rng)
+	menu.SetContinueAvailable(SaveExists(savePath))
+
+	levels := NewLevelManager(DefaultLevels)
+	firstLevel := levels.First()
+
+	g := &Game{
+		scenes:             NewSceneManager(),
+		menu:               menu,
+		player:             NewPlayer(0, 0, float64(screenWidth), float64(screenHeight), 0, nil),
 		lastFrameTime:      0,
-		currentEnvironment: "dust_of_divided_sun",
-		controller:         NewControllerInput(),
+		currentEnvironment: firstLevel.Environment,
+		input:              NewDefaultInputSource(),
 		showCollisionBoxes: false,
 
-		specialItems: []*SpecialItem{
-			NewSchizophrenicFragment(500, 250),
-			NewRealityGlitch(1200, 180),
-			NewSchizophrenicFragment(2400, 220),
-			NewMadnessCore(3000, 130),
-			NewSchizophrenicFragment(4200, 200),
-			NewRealityGlitch(6000, 170),
-			NewSchizophrenicFragment(7000, 200),
-			NewRealityGlitch(7500, 180),
-			NewSchizophrenicFragment(8000, 180),
-			NewSchizophrenicFragment(4000, 180),
-			NewSchizophrenicFragment(4500, 180),
-			NewSchizophrenicFragment(5000, 180),
-			NewSchizophrenicFragment(6000, 250),
-			NewRealityGlitch(1800, 180),
-			NewMadnessCore(2000, 130),
-			NewSchizophrenicFragment(8500, 200),
-			NewSchizophrenicFragment(9000, 200),
-			NewMadnessCore(8500, 120),
-			NewRealityGlitch(2400, 170),
-			NewSchizophrenicFragment(10500, 180),
-			NewRealityGlitch(11000, 170),
-			NewSchizophrenicFragment(11500, 200),
-			NewMadnessCore(11000, 120),
-			NewSchizophrenicFragment(12000, 200),
-			NewSchizophrenicFragment(13000, 200),
-			NewSchizophrenicFragment(13200, 200),
-			NewSchizophrenicFragment(13300, 200),
-			NewSchizophrenicFragment(13400, 200),
-			NewSchizophrenicFragment(13500, 200),
-			NewMadnessCore(13700, 120),
-			NewMadnessCore(13800, 120),
-			NewSchizophrenicFragment(14000, 200),
-			NewRealityGlitch(14400, 180),
-			NewRealityGlitch(14500, 180),
-		},
+		levels: levels,
+
 		collectedItems:      make(map[SpecialItemType]bool),
 		totalItemsCollected: 0,
 		maxItems:            50,
@@ -174,11 +170,11 @@ func NewGame() *Game {
 		currentGlitchMessage: "",
 		madnessLevel:         0,
 		madnessDecayTimer:    0,
-		lastPlayerX:          playerStartX,
+		lastPlayerX:          0,
 		dimensionSlipTimer:   0,
 
-		globalParticleSystem:  NewParticleSystem(50),
-		madnessParticleSystem: NewParticleSystem(40),
+		globalParticleSystem:  NewParticleSystem(50, rng),
+		madnessParticleSystem: NewParticleSystem(40, rng),
 
 		healthDecayTimer:   0,
 		healthDecayRate:    0.1,
@@ -186,393 +182,96 @@ func NewGame() *Game {
 		survivalTimer:      0,
 		difficultyModifier: 1.0,
 
-		endingAnimation: NewEndingAnimation(screenWidth, screenHeight),
-		endingTriggered: false,
-	}
-}
+		checkpointRetention: DefaultCheckpointRetention,
 
-func (g *Game) Update() error {
-	g.controller.Update()
+		endingAnimation: NewEndingAnimation(screenWidth, screenHeight, rng),
+		endingTriggered: false,
 
-	deltaTime := 1.0 / 60.0
-	if ebiten.ActualTPS() > 0 {
-		deltaTime = 1.0 / ebiten.ActualTPS()
-	}
-	if deltaTime > 1.0/20.0 {
-		deltaTime = 1.0 / 20.0
+		clock:    NewFixedClock(DefaultTickRate),
+		rng:      rng,
+		savePath: savePath,
+		events:   NewEventBus(),
 	}
 
-	switch g.state {
-	case GameStateMenu:
-		err := g.menu.Update()
-		if err != nil {
-			return err
-		}
-
-		if g.menu.IsStartSelected() {
-			g.state = GameStatePlaying
-		}
-
-		if g.menu.IsExitSelected() {
-			return ebiten.Termination
-		}
-
-	case GameStatePlaying:
-		pausePressed := inpututil.IsKeyJustPressed(ebiten.KeyEscape) || g.controller.IsPauseJustPressed()
-
-		if pausePressed {
-			g.state = GameStatePaused
-			g.menu.SetPauseState()
-		}
-
-		if inpututil.IsKeyJustPressed(ebiten.KeyC) {
-			g.showCollisionBoxes = !g.showCollisionBoxes
-		}
-
-		if inpututil.IsKeyJustPressed(ebiten.KeyR) {
-			g.player.ResetToSafePosition()
-		}
-
-		g.updateSchizophrenicEffects(deltaTime)
-
-		g.updateChaosAtmosphere(deltaTime)
-
-		g.globalParticleSystem.Update(deltaTime, g.madnessLevel)
-		g.madnessParticleSystem.Update(deltaTime, g.madnessLevel)
-
-		if g.endingTriggered {
-			g.endingAnimation.Update(deltaTime)
-			if g.endingAnimation.ShouldCloseGame() {
-				return ebiten.Termination
-			}
-			return nil
-		}
-
-		g.chaosIntensityLevel = g.madnessLevel * (.5 + 0.3*math.Sin(g.realityGlitchTimer*7.0))
-
-		for _, item := range g.specialItems {
-			item.Update(deltaTime)
-
-			if g.player.IsPerformingAttack() {
-				attackX, attackY, attackW, attackH := g.player.GetAttackBox()
-				if item.CheckHitCollision(attackX, attackY, attackW, attackH) {
-					wasCollected := item.TakeHit()
-					if wasCollected {
-						g.triggerMadness(item.ItemType)
-
-						g.updateProgression(item.ItemType)
-
-						g.spawnCollectionEffect(item.X+item.Width/2, item.Y+item.Height/2, item.ItemType)
-					} else {
-						g.globalParticleSystem.SpawnBurst(item.X+item.Width/2, item.Y+item.Height/2, ParticleTypeHallucinationSpark, 3)
-					}
-				}
-			}
-		}
-
-		madnessMultiplier := 1.0 + g.madnessLevel*3.0
-		chaosOffset := math.Sin(float64(time.Now().Unix())) * 2.0 * g.madnessLevel
-		g.parallaxOffset += (0.5 + chaosOffset) * madnessMultiplier
+	g.player.Events = g.events
+	g.stats = NewRunStats(g.events)
+	g.subscribeGameplayEvents()
 
-		g.player.UpdatePhysicsCorruption(g.specialItems, deltaTime)
-
-		g.player.ApplyMadnessDamage(g.madnessLevel, deltaTime)
-
-		g.checkProximityDamage(deltaTime)
-
-		g.updateDifficultyAndPressure(deltaTime)
-
-		g.player.Update(deltaTime)
+	if err := g.loadLevel(firstLevel.ID); err != nil {
+		log.Printf("Failed to load level %s: %v", firstLevel.ID, err)
+	}
+	g.lastPlayerX = g.player.X
 
-		if g.madnessLevel >= 1.0 {
-			g.player.TakeDamage(999)
-			g.state = GameStateDead
-			g.menu.SetRespawnState()
-		}
+	g.scenes.Push(NewMenuScene(g), NoTransition)
 
-		if g.player.Y >= 1000 {
-			g.state = GameStateDead
-			g.menu.SetRespawnState()
-		}
+	return g
+}
 
-		if g.player.IsPlayerDead() {
-			g.state = GameStateDead
-			g.menu.SetRespawnState()
-		}
+func (g *Game) Update() error {
+	return g.Step(g.clock.Tick(), g.input.Poll())
+}
 
-	case GameStatePaused:
-		err := g.menu.Update()
-		if err != nil {
-			return err
-		}
+func (g *Game) SetInputSource(input InputSource) {
+	g.input = input
+}
 
-		if g.menu.IsContinueRequested() {
-			g.state = GameStatePlaying
-		}
+func (g *Game) SetClock(clock Clock) {
+	g.clock = clock
+}
 
-		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-			g.state = GameStatePlaying
-		}
+// SetSavePath moves saving and loading to path. An empty path turns both off
+// and hides Continue, so the menu doesn't depend on what is on disk; recorded
+// and replayed runs need that to reproduce on another machine.
+func (g *Game) SetSavePath(path string) {
+	g.savePath = path
+	g.menu.SetContinueAvailable(SaveExists(path))
+}
 
-	case GameStateDead:
-		err := g.menu.Update()
-		if err != nil {
-			return err
-		}
+func (g *Game) SimulationTime() float64 {
+	return g.simulationTime
+}
 
-		if g.menu.IsRestartRequested() {
-			g.restartGame()
-			g.state = GameStatePlaying
-		}
+func (g *Game) Seed() int64 {
+	return g.rng.Seed
+}
 
-		if g.menu.GetState() == MenuStateMain {
-			g.state = GameStateMenu
-		}
+func (g *Game) TickCount() int {
+	return g.tickCount
+}
 
-	case GameStateUnionWin:
-		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-			g.restartGame()
-			g.state = GameStateMenu
-		}
-	}
+// Step advances the simulation by one tick of deltaTime seconds using the
+// given input. It does not depend on ebiten's clock or keyboard, so it can be
+// driven from tests, tools or bots.
+func (g *Game) Step(deltaTime float64, input InputState) error {
+	deltaTime = clampDeltaTime(deltaTime)
+	g.simulationTime += deltaTime
+	g.tickCount++
 
-	return nil
+	return g.scenes.Update(deltaTime, input)
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	screenWidth, screenHeight := screen.Bounds().Dx(), screen.Bounds().Dy()
-
-	switch g.state {
-	case GameStateMenu:
-		layers := assets.GetLayersByEnvironment()
-		assets.DrawBackgroundLayers(screen, layers, g.parallaxOffset*0.1, 0, screenWidth)
-		g.menu.Draw(screen)
-
-	case GameStatePlaying:
-		camera := g.player.GetCamera()
-		cameraX, cameraY := camera.GetView()
-
-		cameraX += g.screenShakeX
-		cameraY += g.screenShakeY
-
-		layers := assets.GetLayersByEnvironment()
-
-		if g.isRealityBroken || g.chaosAtmosphereLevel > 0.7 {
-			glitchOffset := g.parallaxOffset * (1.0 + rand.Float64()*0.5)
-			atmosphereOffset := g.chaosAtmosphereLevel * 3.0 * math.Sin(g.realityGlitchTimer*6.0)
-			totalOffsetX := cameraX + glitchOffset + g.screenDistortionX*0.2 + atmosphereOffset
-			totalOffsetY := cameraY + glitchOffset + g.screenDistortionY*0.2 + atmosphereOffset*0.2
-			assets.DrawBackgroundLayers(screen, layers, totalOffsetX, totalOffsetY, screenWidth)
-		} else {
-			distortedX := cameraX + g.screenDistortionX*0.1
-			distortedY := cameraY + g.screenDistortionY*0.1
-			assets.DrawBackgroundLayers(screen, layers, distortedX, distortedY, screenWidth)
-		}
-
-		if assets.DesertTileMap != nil {
-			assets.DesertTileMap.Draw(screen, cameraX, cameraY)
-		}
-
-		for _, item := range g.specialItems {
-			item.Draw(screen, cameraX, cameraY)
-		}
-
-		g.globalParticleSystem.Draw(screen, cameraX, cameraY)
-		g.madnessParticleSystem.Draw(screen, cameraX, cameraY)
-
-		g.drawPlayerWithCamera(screen, camera)
-
-		if g.colorShiftIntensity > 0.01 {
-			limitedIntensity := math.Min(g.colorShiftIntensity, 0.2)
-			alpha := uint8(math.Min(16, 16*limitedIntensity))
-			overlayColor := color.RGBA{
-				uint8(120 * math.Sin(g.realityGlitchTimer*3.0)),
-				uint8(120 * math.Sin(g.realityGlitchTimer*2.0)),
-				uint8(120 * math.Sin(g.realityGlitchTimer*4.0)),
-				alpha,
-			}
-			vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), overlayColor, false)
-		}
-
-		if g.madnessLevel >= 0.8 {
-			criticalIntensity := 0.2
-			pulseIntensity := 5.
-
-			criticalOverlay := color.RGBA{
-				255,
-				0,
-				0,
-				uint8(10 * criticalIntensity * pulseIntensity),
-			}
-			vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), criticalOverlay, false)
-		}
-
-		if g.showCollisionBoxes {
-			px, py, pw, ph := g.player.GetBounds()
-			screenPX, screenPY := camera.WorldToScreen(px, py)
-			vector.StrokeRect(screen, float32(screenPX), float32(screenPY), float32(pw), float32(ph), 1, color.RGBA{0, 255, 0, 255}, false)
-
-			if g.player.IsPerformingAttack() {
-				ax, ay, aw, ah := g.player.GetAttackBox()
-				screenAX, screenAY := camera.WorldToScreen(ax, ay)
-				vector.StrokeRect(screen, float32(screenAX), float32(screenAY), float32(aw), float32(ah), 2, color.RGBA{255, 0, 0, 200}, false)
-			}
-		}
-
-		g.drawHealthBar(screen)
-
-		if g.currentGlitchMessage != "" && g.messageTimer > 0 {
-			messageColor := color.RGBA{
-				uint8(255 * (0.5 + 0.5*math.Sin(g.realityGlitchTimer*20.0))),
-				uint8(100 * (0.5 + 0.5*math.Sin(g.realityGlitchTimer*15.0))),
-				uint8(100 * (0.5 + 0.5*math.Sin(g.realityGlitchTimer*25.0))),
-				255,
-			}
-
-			textX := 50.0 + rand.Float64()*float64(screenWidth-400)
-			textY := 50.0 + rand.Float64()*100.0
-
-			esset.DrawText(screen, g.currentGlitchMessage, textX, textY, assets.FontFaceM, messageColor)
-		}
-
-		if g.madnessLevel > 0 {
-			madnessText := fmt.Sprintf("MADNESS: %.0f%%", g.madnessLevel*100)
-
-			if g.madnessLevel >= 0.9 {
-				madnessText = "⚠️ CRITICAL MADNESS: " + fmt.Sprintf("%.0f%%", g.madnessLevel*100) + " - DEATH IMMINENT! ⚠️"
-			}
-
-			madnessColor := color.RGBA{
-				uint8(255 * g.madnessLevel),
-				uint8(255 * (1.0 - g.madnessLevel)),
-				0,
-				255,
-			}
-
-			if g.madnessLevel >= 0.9 {
-				flashIntensity := 0.1 + 0.1*math.Sin(g.realityGlitchTimer*0.5)
-				madnessColor = color.RGBA{
-					255,
-					uint8(100 * flashIntensity),
-					uint8(100 * flashIntensity),
-					100,
-				}
-			}
-
-			esset.DrawText(screen, madnessText, float64(screenWidth-350), 10, assets.FontFaceS, madnessColor)
-		}
-
-		fps := ebiten.ActualFPS()
-		tps := ebiten.ActualTPS()
-		fpsTpsText := fmt.Sprintf("FPS: %.0f  TPS: %.0f", fps, tps)
-		esset.DrawText(screen, fpsTpsText, 10, 10, assets.FontFaceS, color.RGBA{255, 255, 255, 255})
-
-		if g.endingTriggered {
-			g.endingAnimation.Draw(screen)
-		}
-
-	case GameStatePaused:
-		camera := g.player.GetCamera()
-		cameraX, cameraY := camera.GetView()
-
-		layers := assets.GetLayersByEnvironment()
-		assets.DrawBackgroundLayers(screen, layers, cameraX, cameraY, screenWidth)
-
-		if assets.DesertTileMap != nil {
-			assets.DesertTileMap.Draw(screen, cameraX, cameraY)
-		}
-
-		g.drawPlayerWithCamera(screen, camera)
-
-		px, py, pw, ph := g.player.GetBounds()
-		screenPX, screenPY := camera.WorldToScreen(px, py)
-		vector.StrokeRect(screen, float32(screenPX), float32(screenPY), float32(pw), float32(ph), 1, color.RGBA{0, 255, 0, 255}, false)
-
-		fps := ebiten.ActualFPS()
-		tps := ebiten.ActualTPS()
-		fpsTpsText := fmt.Sprintf("FPS: %.0f  TPS: %.0f", fps, tps)
-		esset.DrawText(screen, fpsTpsText, 10, 10, assets.FontFaceS, color.RGBA{255, 255, 255, 255})
-
-		g.menu.Draw(screen)
-
-	case GameStateDead:
-		camera := g.player.GetCamera()
-		cameraX, cameraY := camera.GetView()
-
-		layers := assets.GetLayersByEnvironment()
-		assets.DrawBackgroundLayers(screen, layers, cameraX, cameraY, screenWidth)
-
-		if assets.DesertTileMap != nil {
-			assets.DesertTileMap.Draw(screen, cameraX, cameraY)
-		}
-
-		g.drawPlayerWithCamera(screen, camera)
-
-		vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight),
-			color.RGBA{0, 0, 0, 120}, false)
-
-		g.menu.Draw(screen)
-
-	case GameStateUnionWin:
-		camera := g.player.GetCamera()
-		cameraX, cameraY := camera.GetView()
-
-		layers := assets.GetLayersByEnvironment()
-		assets.DrawBackgroundLayers(screen, layers, cameraX, cameraY, screenWidth)
-
-		if assets.DesertTileMap != nil {
-			assets.DesertTileMap.Draw(screen, cameraX, cameraY)
-		}
-
-		for _, item := range g.specialItems {
-			if item.Collected {
-				item.Draw(screen, cameraX, cameraY)
-			}
-		}
-
-		g.globalParticleSystem.Draw(screen, cameraX, cameraY)
-		g.madnessParticleSystem.Draw(screen, cameraX, cameraY)
-
-		g.drawPlayerWithCamera(screen, camera)
-
-		unionIntensity := 0.2 + 0.1*math.Sin(g.realityGlitchTimer*2.0)
-		unionOverlay := color.RGBA{255, 255, 200, uint8(50 * unionIntensity)}
-		vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), unionOverlay, false)
-
-		victoryTitle := "🌟 UNION ACHIEVED 🌟"
-		titleX := float64(screenWidth)/2 - 200
-		titleY := float64(screenHeight)/2 - 100
-		esset.DrawText(screen, victoryTitle, titleX, titleY, assets.FontFaceM, color.RGBA{255, 255, 255, 255})
-
-		victoryMessage := "Mind and Matter are One"
-		messageX := float64(screenWidth)/2 - 150
-		messageY := titleY + 50
-		esset.DrawText(screen, victoryMessage, messageX, messageY, assets.FontFaceM, color.RGBA{200, 255, 200, 255})
-
-		statsText := fmt.Sprintf("Chaos Cleansed: %d/%d Items", g.totalItemsCollected, len(g.specialItems))
-		statsX := float64(screenWidth)/2 - 120
-		statsY := messageY + 80
-		esset.DrawText(screen, statsText, statsX, statsY, assets.FontFaceS, color.RGBA{255, 255, 255, 200})
-
-		stabilityText := fmt.Sprintf("World Stability: %.0f%%", g.worldStabilityLevel*100)
-		stabilityX := float64(screenWidth)/2 - 100
-		stabilityY := statsY + 30
-		esset.DrawText(screen, stabilityText, stabilityX, stabilityY, assets.FontFaceS, color.RGBA{255, 255, 255, 200})
-
-		continueText := "Press ESCAPE, ENTER, or SPACE to continue"
-		continueX := float64(screenWidth)/2 - 200
-		continueY := stabilityY + 60
-		continueColor := color.RGBA{255, 255, 255, uint8(150 + 100*math.Sin(g.realityGlitchTimer*4.0))}
-		esset.DrawText(screen, continueText, continueX, continueY, assets.FontFaceS, continueColor)
-	}
+	g.scenes.Draw(screen)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return 1280, 720
 }
 
+// GetState reports the state matching the scene on top of the stack.
 func (g *Game) GetState() GameState {
-	return g.state
+	switch g.scenes.Top().(type) {
+	case *PlayScene, *EndingScene:
+		return GameStatePlaying
+	case *PauseScene:
+		return GameStatePaused
+	case *DeathScene:
+		return GameStateDead
+	case *UnionWinScene:
+		return GameStateUnionWin
+	default:
+		return GameStateMenu
+	}
 }
 
 func (g *Game) IsFullscreenToggleRequested() bool {
@@ -599,6 +298,24 @@ func (g *Game) drawPlayerWithCamera(screen *ebiten.Image, camera *Camera) {
 	}
 }
 
+const dashAfterimageInterval = 0.04
+
+// updateDashTrail leaves afterimages of the player behind while it dashes.
+func (g *Game) updateDashTrail(deltaTime float64) {
+	p := g.player
+	if !p.IsDashing || p.AnimationManager == nil {
+		g.dashTrailTimer = 0
+		return
+	}
+
+	g.dashTrailTimer -= deltaTime
+	if g.dashTrailTimer > 0 {
+		return
+	}
+	g.dashTrailTimer = dashAfterimageInterval
+	g.globalParticleSystem.SpawnAfterimage(p.X, p.Y, p.AnimationManager.CurrentFrame(), p.Scale, !p.FacingRight)
+}
+
 func (g *Game) drawHealthBar(screen *ebiten.Image) {
 	healthBarX := float32(20)
 	healthBarY := float32(50)
@@ -644,14 +361,16 @@ func (g *Game) drawHealthBar(screen *ebiten.Image) {
 }
 
 func (g *Game) restartGame() {
-	g.player.X = 100.0
-	g.player.Y = g.player.GroundLevel - float64(SpriteHeight)*g.player.Scale
+	g.resetTiles()
+
+	g.player.X, g.player.Y = g.playerStartPosition()
 	g.player.VelocityX = 0
 	g.player.VelocityY = 0
 	g.player.OnGround = true
 	g.player.Health = g.player.MaxHealth
 	g.player.IsDead = false
 	g.player.InvulnTimer = 0
+	g.player.ResetAbilities()
 
 	if g.player.Camera != nil {
 		g.player.Camera.X = 0
@@ -660,7 +379,12 @@ func (g *Game) restartGame() {
 		g.player.Camera.TargetY = 0
 	}
 
+	for _, platform := range g.platforms {
+		platform.Reset()
+	}
+
 	g.madnessLevel = 0
+	g.lastMadnessLevel = 0
 	g.madnessDecayTimer = 0
 	g.realityGlitchTimer = 0
 	g.colorShiftIntensity = 0
@@ -676,6 +400,7 @@ func (g *Game) restartGame() {
 
 	g.worldStabilityLevel = 0
 	g.unionProgress = 0
+	g.endingTriggered = false
 	g.totalItemsCollected = 0
 	g.collectedItems = make(map[SpecialItemType]bool)
 
@@ -691,12 +416,14 @@ func (g *Game) restartGame() {
 	}
 
 	if g.globalParticleSystem != nil {
-		g.globalParticleSystem = NewParticleSystem(200)
+		g.globalParticleSystem = NewParticleSystem(200, g.rng)
 	}
 	if g.madnessParticleSystem != nil {
-		g.madnessParticleSystem = NewParticleSystem(100)
+		g.madnessParticleSystem = NewParticleSystem(100, g.rng)
 	}
 
+	g.resetCheckpoints()
+
 	g.parallaxOffset = 0
 }
 
@@ -726,8 +453,8 @@ func (g *Game) updateSchizophrenicEffects(deltaTime float64) {
 	g.colorShiftIntensity *= effectiveMadness
 
 	shakeIntensity := effectiveMadness * 5.0
-	g.screenShakeX = (rand.Float64() - 0.5) * shakeIntensity
-	g.screenShakeY = (rand.Float64() - 0.5) * shakeIntensity
+	g.screenShakeX = (g.rng.Effects.Float64() - 0.5) * shakeIntensity
+	g.screenShakeY = (g.rng.Effects.Float64() - 0.5) * shakeIntensity
 
 	g.messageTimer -= deltaTime
 	messageThreshold := 0.3 * (1.0 - g.worldStabilityLevel*0.5)
@@ -739,7 +466,7 @@ func (g *Game) updateSchizophrenicEffects(deltaTime float64) {
 				"THE FINAL PIECE AWAITS",
 				"MIND AND MATTER SEEK BALANCE",
 			}
-			g.currentGlitchMessage = unionMessages[rand.Intn(len(unionMessages))]
+			g.currentGlitchMessage = unionMessages[g.rng.World.Intn(len(unionMessages))]
 		} else if g.worldStabilityLevel > 0.5 {
 			stabilityMessages := []string{
 				"REALITY IS CRYSTALLIZING...",
@@ -747,11 +474,11 @@ func (g *Game) updateSchizophrenicEffects(deltaTime float64) {
 				"HARMONY RETURNS TO THE VOID",
 				"STABILITY PIERCES THE MADNESS",
 			}
-			g.currentGlitchMessage = stabilityMessages[rand.Intn(len(stabilityMessages))]
+			g.currentGlitchMessage = stabilityMessages[g.rng.World.Intn(len(stabilityMessages))]
 		} else {
-			g.currentGlitchMessage = g.glitchMessages[rand.Intn(len(g.glitchMessages))]
+			g.currentGlitchMessage = g.glitchMessages[g.rng.World.Intn(len(g.glitchMessages))]
 		}
-		g.messageTimer = 2.0 + rand.Float64()*3.0
+		g.messageTimer = 2.0 + g.rng.World.Float64()*3.0
 	}
 
 	g.dimensionSlipTimer += deltaTime
@@ -759,7 +486,7 @@ func (g *Game) updateSchizophrenicEffects(deltaTime float64) {
 		g.dimensionSlipTimer = 0
 	}
 
-	if rand.Float64() < effectiveMadness*0.05*(1.0-g.worldStabilityLevel) {
+	if g.rng.World.Float64() < effectiveMadness*0.05*(1.0-g.worldStabilityLevel) {
 		g.isRealityBroken = !g.isRealityBroken
 	}
 }
@@ -770,24 +497,24 @@ func (g *Game) triggerMadness(itemType SpecialItemType) {
 		g.madnessLevel = math.Min(1.0, g.madnessLevel+0.15)
 		g.currentGlitchMessage = "FRAGMENT CONSUMED... REALITY FRACTURES"
 		g.messageTimer = 4.0
-		g.screenShakeX = (rand.Float64() - 0.5) * 3.0
-		g.screenShakeY = (rand.Float64() - 0.5) * 3.0
+		g.screenShakeX = (g.rng.Effects.Float64() - 0.5) * 3.0
+		g.screenShakeY = (g.rng.Effects.Float64() - 0.5) * 3.0
 
 	case ItemRealityGlitch:
 		g.madnessLevel = math.Min(1.0, g.madnessLevel+0.25)
 		g.currentGlitchMessage = "GLITCH ABSORBED... THE MATRIX BLEEDS"
 		g.messageTimer = 5.0
 		g.isRealityBroken = true
-		g.screenShakeX = (rand.Float64() - 0.5) * 5.0
-		g.screenShakeY = (rand.Float64() - 0.5) * 5.0
+		g.screenShakeX = (g.rng.Effects.Float64() - 0.5) * 5.0
+		g.screenShakeY = (g.rng.Effects.Float64() - 0.5) * 5.0
 
 	case ItemMadnessCore:
 		g.madnessLevel = math.Min(0.8, g.madnessLevel+0.35)
 		g.currentGlitchMessage = "CORE INTEGRATED... MADNESS SURGES BUT YOU SURVIVE"
 		g.messageTimer = 6.0
 		g.isRealityBroken = true
-		g.screenShakeX = (rand.Float64() - 0.5) * 8.0
-		g.screenShakeY = (rand.Float64() - 0.5) * 8.0
+		g.screenShakeX = (g.rng.Effects.Float64() - 0.5) * 8.0
+		g.screenShakeY = (g.rng.Effects.Float64() - 0.5) * 8.0
 
 	case ItemUnionCrystal:
 		g.madnessLevel = 0
@@ -797,10 +524,7 @@ func (g *Game) triggerMadness(itemType SpecialItemType) {
 		g.unionProgress = 1.0
 		g.isRealityBroken = false
 		g.triggerUnionEffect()
-		if !g.endingTriggered {
-			g.endingAnimation.Start()
-			g.endingTriggered = true
-		}
+		g.triggerEnding()
 	default:
 	}
 
@@ -814,8 +538,8 @@ func (g *Game) triggerMadness(itemType SpecialItemType) {
 	g.realityGlitchTimer = 0
 	g.colorShiftIntensity = g.madnessLevel
 
-	g.screenShakeX = (rand.Float64() - 0.5) * 4.0
-	g.screenShakeY = (rand.Float64() - 0.5) * 4.0
+	g.screenShakeX = (g.rng.Effects.Float64() - 0.5) * 4.0
+	g.screenShakeY = (g.rng.Effects.Float64() - 0.5) * 4.0
 }
 
 func (g *Game) updateProgression(itemType SpecialItemType) {
@@ -896,15 +620,14 @@ func (g *Game) updateProgression(itemType SpecialItemType) {
 		}
 	}
 	if allCollected && !unionCrystalExists {
-		g.specialItems = append(g.specialItems, NewUnionCrystal(200, 220))
+		if crystal := g.spawnUnionCrystal(); crystal != nil {
+			Publish(g.events, UnionReady{Crystal: crystal})
+		}
 	}
 
 	if hasUnionCrystal {
 		g.unionProgress = 1.0
-		if !g.endingTriggered {
-			g.endingAnimation.Start()
-			g.endingTriggered = true
-		}
+		g.triggerEnding()
 	} else if collectedItems >= totalItems-1 {
 		g.unionProgress = 0.9
 	} else {
@@ -914,6 +637,29 @@ func (g *Game) updateProgression(itemType SpecialItemType) {
 	g.worldStabilityLevel = math.Max(0, math.Min(1.0, baseStability-chaosReduction+chaosRatio*0.2+g.unionProgress*0.3))
 }
 
+// triggerEnding starts the ending over the level the first time the union
+// is achieved.
+func (g *Game) triggerEnding() {
+	if g.endingTriggered {
+		return
+	}
+	g.endingTriggered = true
+	g.endingAnimation.Start()
+	g.scenes.Push(NewEndingScene(g), NoTransition)
+}
+
+func (g *Game) spawnUnionCrystal() *SpecialItem {
+	if g.unionCrystalSpawn == nil {
+		return nil
+	}
+
+	crystal := NewUnionCrystal(g.unionCrystalSpawn.X, g.unionCrystalSpawn.Y, g.rng)
+	crystal.ObjectID = g.unionCrystalSpawn.ID
+	crystal.applyObjectProperties(*g.unionCrystalSpawn)
+	g.specialItems = append(g.specialItems, crystal)
+	return crystal
+}
+
 func (g *Game) spawnCollectionEffect(x, y float64, itemType SpecialItemType) {
 	switch itemType {
 	case ItemSchizophrenicFragment, ItemRealityGlitch, ItemMadnessCore:
@@ -1025,8 +771,8 @@ func (g *Game) updateChaosAtmosphere(deltaTime float64) {
 		playerX, playerY, _, _ := g.player.GetBounds()
 
 		for i := 0; i < int(g.chaosAtmosphereLevel*2)+1; i++ {
-			particleX := playerX + (rand.Float64()-0.5)*800
-			particleY := playerY + (rand.Float64()-0.5)*600
+			particleX := playerX + (g.rng.Particles.Float64()-0.5)*800
+			particleY := playerY + (g.rng.Particles.Float64()-0.5)*600
 
 			if g.chaosAtmosphereLevel > 0.8 {
 				g.madnessParticleSystem.SpawnParticle(particleX, particleY, ParticleTypeMadness)
@@ -1041,7 +787,7 @@ func (g *Game) updateChaosAtmosphere(deltaTime float64) {
 	}
 
 	if g.chaosAtmosphereLevel > 0.8 {
-		if rand.Float64() < g.chaosAtmosphereLevel*0.005 {
+		if g.rng.World.Float64() < g.chaosAtmosphereLevel*0.005 {
 			g.isRealityBroken = !g.isRealityBroken
 		}
 
@@ -1089,8 +835,8 @@ func (g *Game) checkProximityDamage(deltaTime float64) {
 				g.player.TakeDamage(damageAmount)
 				g.proximityDamageTimer = 0
 
-				g.screenShakeX += (rand.Float64() - 0.5) * 5.0
-				g.screenShakeY += (rand.Float64() - 0.5) * 5.0
+				g.screenShakeX += (g.rng.Effects.Float64() - 0.5) * 5.0
+				g.screenShakeY += (g.rng.Effects.Float64() - 0.5) * 5.0
 				break
 			}
 		}
@@ -0,0 +1,159 @@
+package src
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const determinismTicks = 5000
+
+// determinismScript starts a run from the main menu and then walks, jumps,
+// rolls, attacks and dashes in a fixed pattern for determinismTicks ticks.
+func determinismScript() *ScriptedSource {
+	script := NewScriptedSource().Hold(1, ActionMenuSelect).Hold(60)
+	pattern := []struct {
+		ticks   int
+		actions []Action
+	}{
+		{90, []Action{ActionMoveRight}},
+		{12, []Action{ActionMoveRight, ActionJump}},
+		{30, []Action{ActionMoveRight}},
+		{1, []Action{ActionMoveRight, ActionDash}},
+		{20, nil},
+		{1, []Action{ActionAttack}},
+		{40, []Action{ActionMoveLeft}},
+		{1, []Action{ActionRoll}},
+		{25, nil},
+		{15, []Action{ActionMoveLeft, ActionJump}},
+		{1, []Action{ActionMoveUp, ActionDash}},
+		{30, nil},
+	}
+	for ticks := 61; ticks < determinismTicks; {
+		for _, step := range pattern {
+			script.Hold(step.ticks, step.actions...)
+			ticks += step.ticks
+		}
+	}
+	return script
+}
+
+func runDeterminismScript(t *testing.T, seed int64) (uint64, *Game) {
+	t.Helper()
+
+	g := NewGame(seed)
+	g.SetSavePath("")
+	clock := NewFixedClock(DefaultTickRate)
+	script := determinismScript()
+
+	for g.TickCount() < determinismTicks && !script.Done() {
+		if err := g.Step(clock.Tick(), script.Poll()); err != nil {
+			t.Fatalf("tick %d: %v", g.TickCount(), err)
+		}
+	}
+	return g.StateHash(), g
+}
+
+func TestStepIsDeterministic(t *testing.T) {
+	first, g := runDeterminismScript(t, 42)
+	if g.TickCount() != determinismTicks {
+		t.Fatalf("ran %d ticks, want %d", g.TickCount(), determinismTicks)
+	}
+	if g.GetState() == GameStateMenu {
+		t.Fatal("script never left the main menu")
+	}
+
+	second, _ := runDeterminismScript(t, 42)
+	if first != second {
+		t.Fatalf("state hash differs between identical runs: %x != %x", first, second)
+	}
+}
+
+func TestResumeContinuesRandomStreams(t *testing.T) {
+	_, g := runDeterminismScript(t, 9)
+	encoded, err := json.Marshal(g.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	draw := func() [4]int64 {
+		return [4]int64{g.rng.World.Int63(), g.rng.Items.Int63(), g.rng.Effects.Int63(), g.rng.Particles.Int63()}
+	}
+	want := draw()
+
+	data, err := DecodeSave(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ApplySave(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := draw(); got != want {
+		t.Errorf("draws after resume = %v, want %v", got, want)
+	}
+}
+
+func TestReplayChecksPhysicsProfile(t *testing.T) {
+	g := NewGame(7)
+	recorder := NewReplayRecorder(determinismScript(), 7, DefaultTickRate)
+	clock := NewFixedClock(DefaultTickRate)
+	for range 120 {
+		if err := g.Step(clock.Tick(), recorder.Poll()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.Finish(g).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.PhysicsHash != DefaultPhysicsProfile().Hash() {
+		t.Fatalf("replay physics hash = %016x, want the default profile's", replay.PhysicsHash)
+	}
+
+	playback := NewGame(replay.Seed)
+	if err := replay.CheckPhysics(playback); err != nil {
+		t.Fatalf("same profile rejected: %v", err)
+	}
+
+	profile := DefaultPhysicsProfile()
+	profile.JumpPower++
+	playback.SetPhysicsProfile(profile)
+	if err := replay.CheckPhysics(playback); !errors.Is(err, ErrReplayPhysics) {
+		t.Errorf("CheckPhysics with a changed profile = %v, want ErrReplayPhysics", err)
+	}
+	if err := replay.Verify(playback); !errors.Is(err, ErrReplayPhysics) {
+		t.Errorf("Verify with a changed profile = %v, want ErrReplayPhysics", err)
+	}
+}
+
+func TestUnionCrystalEndsTheRun(t *testing.T) {
+	g := NewGame(1)
+	g.scenes.Switch(NewPlayScene(g), NoTransition)
+
+	crystal := g.spawnUnionCrystal()
+	if crystal == nil {
+		t.Fatal("level has no union crystal spawn")
+	}
+	crystal.Collected = true
+	Publish(g.events, ItemCollected{Item: crystal})
+
+	if _, ok := g.scenes.Top().(*EndingScene); !ok {
+		t.Fatalf("top scene after the union is %T, want *EndingScene", g.scenes.Top())
+	}
+
+	clock := NewFixedClock(DefaultTickRate)
+	for i := 0; i < 60*DefaultTickRate && g.GetState() != GameStateUnionWin; i++ {
+		if err := g.Step(clock.Tick(), InputState{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.GetState() != GameStateUnionWin {
+		t.Fatalf("state after the ending is %v, want GameStateUnionWin", g.GetState())
+	}
+}
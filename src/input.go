@@ -0,0 +1,315 @@
+package src
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+type Action int
+
+const (
+	ActionMoveLeft Action = iota
+	ActionMoveRight
+	ActionJump
+	ActionAttack
+	ActionRoll
+	ActionPause
+	ActionMenuUp
+	ActionMenuDown
+	ActionMenuSelect
+	ActionToggleDebug
+	ActionResetPosition
+	ActionDash
+	ActionMoveUp
+	ActionMoveDown
+	actionCount
+)
+
+type ActionSet uint32
+
+func (s ActionSet) Has(action Action) bool {
+	return s&(1<<uint(action)) != 0
+}
+
+func (s *ActionSet) Add(action Action) {
+	*s |= 1 << uint(action)
+}
+
+// InputState is the logical input for a single tick. MoveAxis carries the
+// analog horizontal value in [-1, 1]; digital sources report -1, 0 or 1.
+type InputState struct {
+	Held        ActionSet
+	JustPressed ActionSet
+	MoveAxis    float64
+}
+
+func (in InputState) IsPressed(action Action) bool {
+	return in.Held.Has(action)
+}
+
+func (in InputState) IsJustPressed(action Action) bool {
+	return in.JustPressed.Has(action)
+}
+
+func (in *InputState) Press(action Action) {
+	in.Held.Add(action)
+	in.JustPressed.Add(action)
+}
+
+func (in InputState) Merge(other InputState) InputState {
+	merged := InputState{
+		Held:        in.Held | other.Held,
+		JustPressed: in.JustPressed | other.JustPressed,
+		MoveAxis:    in.MoveAxis,
+	}
+	if math.Abs(other.MoveAxis) > math.Abs(merged.MoveAxis) {
+		merged.MoveAxis = other.MoveAxis
+	}
+	return merged
+}
+
+// NextInputState builds the state for a tick in which exactly the given
+// actions are held, deriving JustPressed from the previous tick.
+func NextInputState(previous InputState, actions ...Action) InputState {
+	var state InputState
+	for _, action := range actions {
+		state.Held.Add(action)
+	}
+	state.JustPressed = state.Held &^ previous.Held
+	state.MoveAxis = digitalAxis(state.Held)
+	return state
+}
+
+func digitalAxis(held ActionSet) float64 {
+	left := held.Has(ActionMoveLeft)
+	right := held.Has(ActionMoveRight)
+	if left && !right {
+		return -1
+	}
+	if right && !left {
+		return 1
+	}
+	return 0
+}
+
+type InputSource interface {
+	Poll() InputState
+}
+
+type KeyboardSource struct {
+	Keys         map[Action][]ebiten.Key
+	MouseButtons map[Action][]ebiten.MouseButton
+}
+
+func NewKeyboardSource() *KeyboardSource {
+	return &KeyboardSource{
+		Keys: map[Action][]ebiten.Key{
+			ActionMoveLeft:      {ebiten.KeyA, ebiten.KeyArrowLeft},
+			ActionMoveRight:     {ebiten.KeyD, ebiten.KeyArrowRight},
+			ActionJump:          {ebiten.KeySpace},
+			ActionAttack:        {ebiten.KeyJ, ebiten.KeyEnter},
+			ActionRoll:          {ebiten.KeyShift, ebiten.KeyZ},
+			ActionPause:         {ebiten.KeyEscape},
+			ActionMenuUp:        {ebiten.KeyArrowUp, ebiten.KeyW},
+			ActionMenuDown:      {ebiten.KeyArrowDown, ebiten.KeyS},
+			ActionMenuSelect:    {ebiten.KeyEnter, ebiten.KeySpace},
+			ActionToggleDebug:   {ebiten.KeyC},
+			ActionResetPosition: {ebiten.KeyR},
+			ActionDash:          {ebiten.KeyX, ebiten.KeyL},
+			ActionMoveUp:        {ebiten.KeyW, ebiten.KeyArrowUp},
+			ActionMoveDown:      {ebiten.KeyS, ebiten.KeyArrowDown},
+		},
+		MouseButtons: map[Action][]ebiten.MouseButton{
+			ActionAttack: {ebiten.MouseButtonLeft},
+		},
+	}
+}
+
+func (k *KeyboardSource) Bind(action Action, keys ...ebiten.Key) {
+	k.Keys[action] = keys
+}
+
+func (k *KeyboardSource) Poll() InputState {
+	var state InputState
+	for action, keys := range k.Keys {
+		for _, key := range keys {
+			if ebiten.IsKeyPressed(key) {
+				state.Held.Add(action)
+			}
+			if inpututil.IsKeyJustPressed(key) {
+				state.JustPressed.Add(action)
+			}
+		}
+	}
+	for action, buttons := range k.MouseButtons {
+		for _, button := range buttons {
+			if ebiten.IsMouseButtonPressed(button) {
+				state.Held.Add(action)
+			}
+			if inpututil.IsMouseButtonJustPressed(button) {
+				state.JustPressed.Add(action)
+			}
+		}
+	}
+	state.MoveAxis = digitalAxis(state.Held)
+	return state
+}
+
+type GamepadSource struct {
+	Controller *ControllerInput
+
+	edges heldEdges
+}
+
+func NewGamepadSource() *GamepadSource {
+	return &GamepadSource{Controller: NewControllerInput()}
+}
+
+func (g *GamepadSource) Poll() InputState {
+	c := g.Controller
+	c.Update()
+
+	var state InputState
+	if !c.IsActive() {
+		g.edges = heldEdges{}
+		return state
+	}
+
+	if c.IsLeftPressed() {
+		state.Held.Add(ActionMoveLeft)
+	}
+	if c.IsRightPressed() {
+		state.Held.Add(ActionMoveRight)
+	}
+	if c.IsUpPressed() {
+		state.Held.Add(ActionMoveUp)
+	}
+	if c.IsDownPressed() {
+		state.Held.Add(ActionMoveDown)
+	}
+	if c.IsJumpPressed() {
+		state.Held.Add(ActionJump)
+	}
+
+	justPressed := map[Action]bool{
+		ActionJump:       c.IsJumpJustPressed(),
+		ActionRoll:       c.IsRollJustPressed(),
+		ActionDash:       c.IsDashJustPressed(),
+		ActionAttack:     c.IsAttackJustPressed(),
+		ActionPause:      c.IsPauseJustPressed(),
+		ActionMenuUp:     c.IsUpJustPressed(),
+		ActionMenuDown:   c.IsDownJustPressed(),
+		ActionMenuSelect: c.IsSelectJustPressed(),
+	}
+	for action, pressed := range justPressed {
+		if pressed {
+			state.Press(action)
+		}
+	}
+
+	// The controller only reports presses of the d-pad, and the stick has
+	// none at all, so up and down are pressed when they start being held.
+	g.edges.press(&state, ActionMoveUp, ActionMoveDown)
+
+	state.MoveAxis = c.GetHorizontalAxis()
+	return state
+}
+
+// heldEdges reports held actions as just pressed on the tick they start
+// being held, for sources that only know whether they are down.
+type heldEdges struct {
+	previous ActionSet
+}
+
+func (e *heldEdges) press(state *InputState, actions ...Action) {
+	for _, action := range actions {
+		if state.Held.Has(action) && !e.previous.Has(action) {
+			state.JustPressed.Add(action)
+		}
+	}
+	e.previous = state.Held
+}
+
+// MultiSource merges several sources, e.g. keyboard and gamepad together.
+type MultiSource []InputSource
+
+func (m MultiSource) Poll() InputState {
+	var state InputState
+	for _, source := range m {
+		state = state.Merge(source.Poll())
+	}
+	return state
+}
+
+func NewDefaultInputSource() InputSource {
+	return MultiSource{NewKeyboardSource(), NewGamepadSource()}
+}
+
+// ScriptedSource plays back a fixed list of states, one per Poll, and reports
+// an empty state once exhausted. Useful for tests and bots.
+type ScriptedSource struct {
+	frames []InputState
+	index  int
+}
+
+func NewScriptedSource(frames ...InputState) *ScriptedSource {
+	return &ScriptedSource{frames: frames}
+}
+
+func (s *ScriptedSource) Hold(ticks int, actions ...Action) *ScriptedSource {
+	previous := InputState{}
+	if len(s.frames) > 0 {
+		previous = s.frames[len(s.frames)-1]
+	}
+	for i := 0; i < ticks; i++ {
+		previous = NextInputState(previous, actions...)
+		s.frames = append(s.frames, previous)
+	}
+	return s
+}
+
+func (s *ScriptedSource) Poll() InputState {
+	if s.index >= len(s.frames) {
+		return InputState{}
+	}
+	state := s.frames[s.index]
+	s.index++
+	return state
+}
+
+func (s *ScriptedSource) Done() bool {
+	return s.index >= len(s.frames)
+}
+
+// RemoteSource receives states pushed from another goroutine, such as a
+// network connection. When no new state arrived for a tick the last held
+// actions are repeated without retriggering JustPressed.
+type RemoteSource struct {
+	states chan InputState
+	last   InputState
+}
+
+func NewRemoteSource(buffer int) *RemoteSource {
+	return &RemoteSource{states: make(chan InputState, buffer)}
+}
+
+func (r *RemoteSource) Push(state InputState) bool {
+	select {
+	case r.states <- state:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *RemoteSource) Poll() InputState {
+	select {
+	case state := <-r.states:
+		r.last = state
+		return state
+	default:
+		return InputState{Held: r.last.Held, MoveAxis: r.last.MoveAxis}
+	}
+}
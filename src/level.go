@@ -0,0 +1,235 @@
+package src
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/temidaradev/ebijam25/assets"
+)
+
+const (
+	ExitZoneObjectType    = "exit"
+	PlayerStartObjectType = "player_start"
+)
+
+// LevelDefinition describes one stage. ItemLayer names the object layer
+// items are read from; when empty, item objects from every layer are used.
+// Music is the ID of the track for the stage, for when the game has audio.
+type LevelDefinition struct {
+	ID          string
+	Name        string
+	MapPath     string
+	Environment string
+	ItemLayer   string
+	Music       string
+}
+
+var DefaultLevels = []LevelDefinition{
+	{
+		ID:          "desert",
+		Name:        "DUST OF THE DIVIDED SUN",
+		MapPath:     assets.DesertMapPath,
+		Environment: assets.DefaultEnvironment,
+		ItemLayer:   "Items",
+		Music:       "desert_theme",
+	},
+	{
+		ID:          "ruins",
+		Name:        "RUINS OF THE SPLIT MIND",
+		MapPath:     assets.RuinsMapPath,
+		Environment: assets.DefaultEnvironment,
+		ItemLayer:   "Items",
+		Music:       "ruins_theme",
+	},
+}
+
+type LevelManager struct {
+	levels  []LevelDefinition
+	current int
+}
+
+func NewLevelManager(levels []LevelDefinition) *LevelManager {
+	return &LevelManager{levels: levels}
+}
+
+func (lm *LevelManager) Current() LevelDefinition {
+	return lm.levels[lm.current]
+}
+
+func (lm *LevelManager) First() LevelDefinition {
+	return lm.levels[0]
+}
+
+func (lm *LevelManager) Find(id string) (int, bool) {
+	for i, level := range lm.levels {
+		if level.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Next returns the level after the current one, if any.
+func (lm *LevelManager) Next() (LevelDefinition, bool) {
+	if lm.current+1 >= len(lm.levels) {
+		return LevelDefinition{}, false
+	}
+	return lm.levels[lm.current+1], true
+}
+
+// Load makes the level current and returns a copy of its map for the game
+// to edit, leaving the cached map untouched.
+func (lm *LevelManager) Load(id string) (LevelDefinition, *assets.TileMap, error) {
+	index, ok := lm.Find(id)
+	if !ok {
+		return LevelDefinition{}, nil, fmt.Errorf("level: unknown level %q", id)
+	}
+
+	level := lm.levels[index]
+	tileMap := assets.GetTileMap(level.MapPath)
+	if tileMap == nil {
+		return LevelDefinition{}, nil, fmt.Errorf("level: failed to load map %s", level.MapPath)
+	}
+
+	lm.current = index
+	return level, tileMap.Clone(), nil
+}
+
+// ExitZone moves the player to Target when entered. An empty Target means
+// the next level in the registry.
+type ExitZone struct {
+	X, Y          float64
+	Width, Height float64
+	Target        string
+}
+
+func LoadExitZones(tileMap *assets.TileMap) []ExitZone {
+	var zones []ExitZone
+	for _, object := range tileMap.ObjectsOfType(ExitZoneObjectType) {
+		zones = append(zones, ExitZone{
+			X:      object.X,
+			Y:      object.Y,
+			Width:  object.Width,
+			Height: object.Height,
+			Target: object.Properties.GetString("target"),
+		})
+	}
+	return zones
+}
+
+func (z ExitZone) Overlaps(x, y, width, height float64) bool {
+	return x < z.X+z.Width && x+width > z.X && y < z.Y+z.Height && y+height > z.Y
+}
+
+func (g *Game) CurrentLevel() LevelDefinition {
+	return g.levels.Current()
+}
+
+// backgroundLayers returns the current map's backgrounds, falling back to
+// the environment's while no map is loaded.
+func (g *Game) backgroundLayers() []assets.BackgroundLayer {
+	if g.tileMap != nil && len(g.tileMap.Backgrounds) > 0 {
+		return g.tileMap.Backgrounds
+	}
+	return assets.GetLayersByEnvironment(g.currentEnvironment)
+}
+
+// loadLevel swaps the active map and rebuilds everything placed on it.
+func (g *Game) loadLevel(id string) error {
+	level, tileMap, err := g.levels.Load(id)
+	if err != nil {
+		return err
+	}
+
+	g.tileMap = tileMap
+	g.currentEnvironment = level.Environment
+
+	objects := tileMap.Objects
+	if level.ItemLayer != "" {
+		objects = tileMap.ObjectsInLayer(level.ItemLayer)
+	}
+	g.specialItems = LoadSpecialItems(objects, g.rng)
+
+	g.unionCrystalSpawn = nil
+	if spawns := tileMap.ObjectsOfType(UnionCrystalSpawnType); len(spawns) > 0 {
+		g.unionCrystalSpawn = &spawns[0]
+	}
+
+	g.levelStart = nil
+	if starts := tileMap.ObjectsOfType(PlayerStartObjectType); len(starts) > 0 {
+		g.levelStart = &starts[0]
+	}
+
+	g.checkpoints = LoadCheckpoints(tileMap)
+	g.activeCheckpoint = nil
+	g.exitZones = LoadExitZones(tileMap)
+
+	g.platforms = LoadMovingPlatforms(tileMap)
+
+	g.player.UpdateCollisionSystem(tileMap)
+	g.player.CollisionSystem.Platforms = g.platforms
+	g.player.X, g.player.Y = g.playerStartPosition()
+	g.player.VelocityX = 0
+	g.player.VelocityY = 0
+	g.player.OnGround = false
+
+	if g.player.Camera != nil {
+		g.player.Camera.X = 0
+		g.player.Camera.Y = 0
+		g.player.Camera.TargetX = 0
+		g.player.Camera.TargetY = 0
+	}
+
+	return nil
+}
+
+// resetTiles swaps an edited map for a fresh copy of the level's map.
+func (g *Game) resetTiles() {
+	if g.tileMap == nil || !g.tileMap.Edited() {
+		return
+	}
+	original := assets.GetTileMap(g.CurrentLevel().MapPath)
+	if original == nil {
+		return
+	}
+
+	g.tileMap = original.Clone()
+	g.player.UpdateCollisionSystem(g.tileMap)
+	g.player.CollisionSystem.Platforms = g.platforms
+}
+
+func (g *Game) playerStartPosition() (x, y float64) {
+	if g.levelStart != nil {
+		return g.levelStart.X, g.levelStart.Y
+	}
+	return 100.0, g.player.GroundLevel - float64(SpriteHeight)*g.player.Scale
+}
+
+func (g *Game) checkExitZones() {
+	if g.scenes.InTransition() {
+		return
+	}
+
+	px, py, pw, ph := g.player.GetBounds()
+	for _, zone := range g.exitZones {
+		if !zone.Overlaps(px, py, pw, ph) {
+			continue
+		}
+
+		target := zone.Target
+		if target == "" {
+			next, ok := g.levels.Next()
+			if !ok {
+				continue
+			}
+			target = next.ID
+		}
+
+		g.scenes.Run(FadeTransition, func() {
+			if err := g.loadLevel(target); err != nil {
+				log.Printf("Level transition failed: %v", err)
+			}
+		})
+		return
+	}
+}
@@ -0,0 +1,84 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/temidaradev/ebijam25/assets"
+)
+
+func TestDefaultLevelsLoadAndExitsResolve(t *testing.T) {
+	levels := NewLevelManager(DefaultLevels)
+	for _, def := range DefaultLevels {
+		_, tileMap, err := levels.Load(def.ID)
+		if err != nil {
+			t.Fatalf("%s: %v", def.ID, err)
+		}
+		if def.Music == "" {
+			t.Errorf("%s: no music", def.ID)
+		}
+
+		for _, zone := range LoadExitZones(tileMap) {
+			if zone.Target == "" {
+				if _, ok := levels.Next(); !ok {
+					t.Errorf("%s: exit at %.0f,%.0f has no target and no next level", def.ID, zone.X, zone.Y)
+				}
+				continue
+			}
+			if _, ok := levels.Find(zone.Target); !ok {
+				t.Errorf("%s: exit targets unknown level %q", def.ID, zone.Target)
+			}
+		}
+	}
+
+	_, desert, _ := levels.Load("desert")
+	if len(LoadExitZones(desert)) == 0 {
+		t.Error("desert has no exit zone")
+	}
+
+	g := NewGame(1)
+	if err := g.loadLevel("ruins"); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.platforms) != 2 {
+		t.Errorf("ruins has %d moving platforms, want 2", len(g.platforms))
+	}
+	for _, platform := range g.platforms {
+		if len(platform.stops) < 2 {
+			t.Errorf("platform %d has no path", platform.ID)
+		}
+	}
+	if music := g.CurrentLevel().Music; music != "ruins_theme" {
+		t.Errorf("current level music = %q, want ruins_theme", music)
+	}
+}
+
+func TestRestartDiscardsTileEdits(t *testing.T) {
+	g := NewGame(1)
+	layer, ok := g.tileMap.LayerIndex("Tile Layer 1")
+	if !ok {
+		t.Fatal("desert has no tile layer")
+	}
+	const tileX, tileY = 3, 12
+	gid := g.tileMap.TileGIDAt(tileX, tileY, layer)
+	if gid == 0 {
+		t.Fatal("no floor tile to clear")
+	}
+
+	if err := g.tileMap.ClearTile(tileX, tileY, layer); err != nil {
+		t.Fatal(err)
+	}
+	if cached := assets.GetTileMap(assets.DesertMapPath); cached.TileGIDAt(tileX, tileY, layer) != gid {
+		t.Error("clearing a tile changed the cached map")
+	}
+
+	g.restartGame()
+	if got := g.tileMap.TileGIDAt(tileX, tileY, layer); got != gid {
+		t.Errorf("tile after restart = %d, want %d", got, gid)
+	}
+	if g.tileMap.CollisionShapeAt(layer, tileX, tileY) == nil {
+		t.Error("restored tile has no collision")
+	}
+	if g.player.TileMap != g.tileMap {
+		t.Error("player still collides with the edited map")
+	}
+}
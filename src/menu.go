@@ -3,11 +3,8 @@ package src
 import (
 	"image/color"
 	"math"
-	"math/rand"
-	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/temidaradev/ebijam25/assets"
 	"github.com/temidaradev/esset/v2"
@@ -40,20 +37,25 @@ type Menu struct {
 	startGameRequested        bool
 	exitRequested             bool
 	continueRequested         bool
+	loadRequested             bool
+	saveRequested             bool
+	continueAvailable         bool
+	loadItem                  MenuItem
 	restartRequested          bool
 	fullscreenToggleRequested bool
-	controller                *ControllerInput
+
+	rng *RandomStreams
 }
 
-func NewMenu() *Menu {
+func NewMenu(rng *RandomStreams) *Menu {
 	m := &Menu{
+		rng:             rng,
 		state:           MenuStateMain,
 		previousState:   MenuStateMain,
 		selectedIndex:   0,
 		animationTime:   0,
 		transitionAlpha: 1.0,
 		backgroundAlpha: 0.8,
-		controller:      NewControllerInput(),
 	}
 
 	m.menuItems = []MenuItem{
@@ -67,20 +69,33 @@ func NewMenu() *Menu {
 		}},
 	}
 
+	m.loadItem = MenuItem{Text: "CONTINUE", Action: func() MenuState {
+		m.loadRequested = true
+		return MenuStateMain
+	}}
+
 	m.pauseItems = []MenuItem{
 		{Text: "CONTINUE", Action: func() MenuState {
 			m.continueRequested = true
 			return MenuStatePause
 		}},
+		{Text: "SAVE GAME", Action: func() MenuState {
+			m.saveRequested = true
+			return MenuStatePause
+		}},
 		{Text: "EXIT GAME", Action: func() MenuState {
-			os.Exit(0)
+			m.exitRequested = true
 			return MenuStatePause
 		}},
 	}
 
 	m.respawnItems = []MenuItem{
+		{Text: "RESPAWN", Action: func() MenuState {
+			m.restartRequested = true
+			return MenuStateRespawn
+		}},
 		{Text: "QUIT GAME", Action: func() MenuState {
-			os.Exit(1)
+			m.exitRequested = true
 			return MenuStateRespawn
 		}},
 	}
@@ -88,20 +103,14 @@ func NewMenu() *Menu {
 	return m
 }
 
-func (m *Menu) Update() error {
-	m.animationTime += 1.0 / 60.0
-
-	m.controller.Update()
+func (m *Menu) Update(deltaTime float64, input InputState) error {
+	m.animationTime += deltaTime
 
 	currentItems := m.getCurrentMenuItems()
 
-	upPressed := inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW)
-	downPressed := inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS)
-	selectPressed := inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace)
-
-	upPressed = upPressed || m.controller.IsUpJustPressed()
-	downPressed = downPressed || m.controller.IsDownJustPressed()
-	selectPressed = selectPressed || m.controller.IsSelectJustPressed()
+	upPressed := input.IsJustPressed(ActionMenuUp)
+	downPressed := input.IsJustPressed(ActionMenuDown)
+	selectPressed := input.IsJustPressed(ActionMenuSelect)
 
 	if upPressed {
 		m.selectedIndex--
@@ -154,11 +163,11 @@ func (m *Menu) drawMainMenu(screen *ebiten.Image, screenWidth, screenHeight int)
 	glitchPhase := m.animationTime * 3.0
 	titleAlpha := 0.8 + 0.2*math.Sin(glitchPhase)
 
-	if rand.Float64() < 0.1 {
+	if m.rng.Render.Float64() < 0.1 {
 		titleColor := color.RGBA{
-			uint8(200 + rand.Intn(56)),
-			uint8(50 + rand.Intn(100)),
-			uint8(200 + rand.Intn(56)),
+			uint8(200 + m.rng.Render.Intn(56)),
+			uint8(50 + m.rng.Render.Intn(100)),
+			uint8(200 + m.rng.Render.Intn(56)),
 			uint8(titleAlpha * 255),
 		}
 		esset.DrawText(screen, titleText, titleX, titleY, assets.FontFaceM, titleColor)
@@ -177,7 +186,7 @@ func (m *Menu) drawMainMenu(screen *ebiten.Image, screenWidth, screenHeight int)
 	disclaimerY := float64(screenHeight) * 0.32
 	esset.DrawText(screen, disclaimerText, disclaimerX, disclaimerY, assets.FontFaceS, color.RGBA{255, 200, 100, 255})
 
-	m.drawMenuItems(screen, m.menuItems, screenWidth, screenHeight)
+	m.drawMenuItems(screen, m.mainMenuItems(), screenWidth, screenHeight)
 }
 
 func (m *Menu) drawPauseMenu(screen *ebiten.Image, screenWidth, screenHeight int) {
@@ -261,8 +270,19 @@ func (m *Menu) getCurrentMenuItems() []MenuItem {
 	case MenuStateRespawn:
 		return m.respawnItems
 	default:
+		return m.mainMenuItems()
+	}
+}
+
+func (m *Menu) mainMenuItems() []MenuItem {
+	if !m.continueAvailable {
 		return m.menuItems
 	}
+	return append([]MenuItem{m.loadItem}, m.menuItems...)
+}
+
+func (m *Menu) SetContinueAvailable(available bool) {
+	m.continueAvailable = available
 }
 
 func (m *Menu) GetState() MenuState {
@@ -293,6 +313,22 @@ func (m *Menu) IsContinueRequested() bool {
 	return false
 }
 
+func (m *Menu) IsLoadRequested() bool {
+	if m.loadRequested {
+		m.loadRequested = false
+		return true
+	}
+	return false
+}
+
+func (m *Menu) IsSaveRequested() bool {
+	if m.saveRequested {
+		m.saveRequested = false
+		return true
+	}
+	return false
+}
+
 func (m *Menu) IsFullscreenToggleRequested() bool {
 	if m.fullscreenToggleRequested {
 		m.fullscreenToggleRequested = false
@@ -309,6 +345,11 @@ func (m *Menu) IsRestartRequested() bool {
 	return false
 }
 
+func (m *Menu) SetMainState() {
+	m.state = MenuStateMain
+	m.selectedIndex = 0
+}
+
 func (m *Menu) SetPauseState() {
 	m.state = MenuStatePause
 	m.selectedIndex = 0
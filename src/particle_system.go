@@ -3,7 +3,6 @@ package src
 import (
 	"image/color"
 	"math"
-	"math/rand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -23,6 +22,7 @@ const (
 	ParticleTypeHarmonyOrb
 	ParticleTypeUnionBeam
 	ParticleTypeRealityRestore
+	ParticleTypeAfterimage
 )
 
 type Particle struct {
@@ -44,6 +44,11 @@ type Particle struct {
 	AimStrength    float64
 	TrailLength    int
 	TrailPositions []struct{ X, Y float64 }
+
+	// Image and FlipX are the sprite an afterimage copies. Its X, Y is the
+	// sprite's top-left corner and Size its scale.
+	Image *ebiten.Image
+	FlipX bool
 }
 
 type ParticleSystem struct {
@@ -54,12 +59,15 @@ type ParticleSystem struct {
 	ScreenShakeY   float64
 	GlitchTimer    float64
 	ChaosIntensity float64
+
+	rng *RandomStreams
 }
 
-func NewParticleSystem(maxParticles int) *ParticleSystem {
+func NewParticleSystem(maxParticles int, rng *RandomStreams) *ParticleSystem {
 	return &ParticleSystem{
 		Particles:    make([]*Particle, 0),
 		MaxParticles: maxParticles,
+		rng:          rng,
 	}
 }
 
@@ -69,8 +77,8 @@ func (ps *ParticleSystem) Update(deltaTime float64, madnessLevel float64) {
 	ps.ChaosIntensity = madnessLevel * (1.0 + 0.5*math.Sin(ps.GlitchTimer*5.0))
 
 	shakeIntensity := madnessLevel * 8.0
-	ps.ScreenShakeX = (rand.Float64() - 0.5) * shakeIntensity
-	ps.ScreenShakeY = (rand.Float64() - 0.5) * shakeIntensity
+	ps.ScreenShakeX = (ps.rng.Particles.Float64() - 0.5) * shakeIntensity
+	ps.ScreenShakeY = (ps.rng.Particles.Float64() - 0.5) * shakeIntensity
 
 	for i := len(ps.Particles) - 1; i >= 0; i-- {
 		particle := ps.Particles[i]
@@ -82,7 +90,7 @@ func (ps *ParticleSystem) Update(deltaTime float64, madnessLevel float64) {
 	}
 
 	if madnessLevel > 0.2 && len(ps.Particles) < ps.MaxParticles {
-		if rand.Float64() < madnessLevel*0.3 {
+		if ps.rng.Particles.Float64() < madnessLevel*0.3 {
 			ps.SpawnAmbientMadnessParticles()
 		}
 	}
@@ -116,18 +124,18 @@ func (ps *ParticleSystem) updateParticle(p *Particle, deltaTime float64) {
 
 	switch p.ParticleType {
 	case ParticleTypeMadness:
-		p.VelocityX += (rand.Float64() - 0.5) * 100 * ps.ChaosIntensity * deltaTime
-		p.VelocityY += (rand.Float64() - 0.5) * 100 * ps.ChaosIntensity * deltaTime
+		p.VelocityX += (ps.rng.Particles.Float64() - 0.5) * 100 * ps.ChaosIntensity * deltaTime
+		p.VelocityY += (ps.rng.Particles.Float64() - 0.5) * 100 * ps.ChaosIntensity * deltaTime
 
-		if rand.Float64() < 0.2 {
-			p.Color.R = uint8(100 + rand.Intn(156))
-			p.Color.G = uint8(rand.Intn(100))
-			p.Color.B = uint8(150 + rand.Intn(106))
+		if ps.rng.Particles.Float64() < 0.2 {
+			p.Color.R = uint8(100 + ps.rng.Particles.Intn(156))
+			p.Color.G = uint8(ps.rng.Particles.Intn(100))
+			p.Color.B = uint8(150 + ps.rng.Particles.Intn(106))
 		}
 	case ParticleTypeGlitch:
-		if rand.Float64() < 0.05*ps.ChaosIntensity {
-			p.X += (rand.Float64() - 0.5) * 100
-			p.Y += (rand.Float64() - 0.5) * 100
+		if ps.rng.Particles.Float64() < 0.05*ps.ChaosIntensity {
+			p.X += (ps.rng.Particles.Float64() - 0.5) * 100
+			p.Y += (ps.rng.Particles.Float64() - 0.5) * 100
 		}
 
 		p.Size = 2 + 8*math.Sin(ps.GlitchTimer*20+p.Rotation)
@@ -136,7 +144,7 @@ func (ps *ParticleSystem) updateParticle(p *Particle, deltaTime float64) {
 		p.VelocityY *= 0.95
 		p.Size += 10 * deltaTime
 
-		if rand.Float64() < 0.3 && len(ps.Particles) < ps.MaxParticles-5 {
+		if ps.rng.Particles.Float64() < 0.3 && len(ps.Particles) < ps.MaxParticles-5 {
 			ps.SpawnChildParticle(p)
 		}
 	case ParticleTypeChaosOrb:
@@ -174,7 +182,7 @@ func (ps *ParticleSystem) updateParticle(p *Particle, deltaTime float64) {
 		p.VelocityY *= 0.95
 		p.Size += 15 * deltaTime
 
-		if rand.Float64() < 0.1 {
+		if ps.rng.Particles.Float64() < 0.1 {
 			ps.stabilizeNearbyParticles(p.X, p.Y, 50)
 		}
 
@@ -204,7 +212,7 @@ func (ps *ParticleSystem) updateParticle(p *Particle, deltaTime float64) {
 		p.VelocityX *= 0.9
 		p.VelocityY *= 0.9
 
-		if rand.Float64() < 0.2 && len(ps.Particles) < ps.MaxParticles-3 {
+		if ps.rng.Particles.Float64() < 0.2 && len(ps.Particles) < ps.MaxParticles-3 {
 			ps.SpawnHealingAura(p.X, p.Y)
 		}
 	default:
@@ -232,56 +240,56 @@ func (ps *ParticleSystem) SpawnParticle(x, y float64, particleType ParticleType)
 		X:            x,
 		Y:            y,
 		ParticleType: particleType,
-		Rotation:     rand.Float64() * math.Pi * 2,
+		Rotation:     ps.rng.Particles.Float64() * math.Pi * 2,
 		Scale:        1.0,
 	}
 
 	switch particleType {
 	case ParticleTypeMadness:
-		particle.VelocityX = (rand.Float64() - 0.5) * 200
-		particle.VelocityY = (rand.Float64() - 0.5) * 200
-		particle.Size = 3 + rand.Float64()*8
-		particle.Life = 2.0 + rand.Float64()*3.0
+		particle.VelocityX = (ps.rng.Particles.Float64() - 0.5) * 200
+		particle.VelocityY = (ps.rng.Particles.Float64() - 0.5) * 200
+		particle.Size = 3 + ps.rng.Particles.Float64()*8
+		particle.Life = 2.0 + ps.rng.Particles.Float64()*3.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{200, 50, 255, 255}
-		particle.RotationSpeed = (rand.Float64() - 0.5) * 10
+		particle.RotationSpeed = (ps.rng.Particles.Float64() - 0.5) * 10
 		particle.TrailLength = 5
 
 	case ParticleTypeGlitch:
-		particle.VelocityX = (rand.Float64() - 0.5) * 400
-		particle.VelocityY = (rand.Float64() - 0.5) * 400
-		particle.Size = 2 + rand.Float64()*6
-		particle.Life = 1.0 + rand.Float64()*2.0
+		particle.VelocityX = (ps.rng.Particles.Float64() - 0.5) * 400
+		particle.VelocityY = (ps.rng.Particles.Float64() - 0.5) * 400
+		particle.Size = 2 + ps.rng.Particles.Float64()*6
+		particle.Life = 1.0 + ps.rng.Particles.Float64()*2.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{255, 255, 0, 255}
-		particle.RotationSpeed = (rand.Float64() - 0.5) * 20
+		particle.RotationSpeed = (ps.rng.Particles.Float64() - 0.5) * 20
 
 	case ParticleTypeDimensionRip:
-		particle.VelocityX = (rand.Float64() - 0.5) * 50
-		particle.VelocityY = (rand.Float64() - 0.5) * 50
-		particle.Size = 20 + rand.Float64()*30
-		particle.Life = 5.0 + rand.Float64()*3.0
+		particle.VelocityX = (ps.rng.Particles.Float64() - 0.5) * 50
+		particle.VelocityY = (ps.rng.Particles.Float64() - 0.5) * 50
+		particle.Size = 20 + ps.rng.Particles.Float64()*30
+		particle.Life = 5.0 + ps.rng.Particles.Float64()*3.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{120, 0, 120, 180}
-		particle.RotationSpeed = (rand.Float64() - 0.5) * 5
+		particle.RotationSpeed = (ps.rng.Particles.Float64() - 0.5) * 5
 
 	case ParticleTypeChaosOrb:
 		particle.VelocityX = 0
 		particle.VelocityY = 0
-		particle.Size = 15 + rand.Float64()*20
-		particle.Life = 4.0 + rand.Float64()*4.0
+		particle.Size = 15 + ps.rng.Particles.Float64()*20
+		particle.Life = 4.0 + ps.rng.Particles.Float64()*4.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{255, 100, 100, 200}
 		particle.RotationSpeed = 3.0
 		particle.TrailLength = 8
 
 	case ParticleTypeEnergyBeam:
-		angle := rand.Float64() * math.Pi * 2
-		speed := 100 + rand.Float64()*150
+		angle := ps.rng.Particles.Float64() * math.Pi * 2
+		speed := 100 + ps.rng.Particles.Float64()*150
 		particle.VelocityX = math.Cos(angle) * speed
 		particle.VelocityY = math.Sin(angle) * speed
-		particle.Size = 4 + rand.Float64()*8
-		particle.Life = 3.0 + rand.Float64()*2.0
+		particle.Size = 4 + ps.rng.Particles.Float64()*8
+		particle.Life = 3.0 + ps.rng.Particles.Float64()*2.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{0, 255, 255, 255}
 		particle.IsAiming = false
@@ -289,50 +297,50 @@ func (ps *ParticleSystem) SpawnParticle(x, y float64, particleType ParticleType)
 		particle.TrailLength = 10
 
 	case ParticleTypeHallucinationSpark:
-		particle.VelocityX = (rand.Float64() - 0.5) * 300
-		particle.VelocityY = (rand.Float64() - 0.5) * 300
-		particle.Size = 1 + rand.Float64()*4
-		particle.Life = 0.5 + rand.Float64()*1.5
+		particle.VelocityX = (ps.rng.Particles.Float64() - 0.5) * 300
+		particle.VelocityY = (ps.rng.Particles.Float64() - 0.5) * 300
+		particle.Size = 1 + ps.rng.Particles.Float64()*4
+		particle.Life = 0.5 + ps.rng.Particles.Float64()*1.5
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{255, 150, 150, 200}
-		particle.RotationSpeed = (rand.Float64() - 0.5) * 30
+		particle.RotationSpeed = (ps.rng.Particles.Float64() - 0.5) * 30
 
 	case ParticleTypeHealingLight:
-		particle.VelocityX = (rand.Float64() - 0.5) * 50
-		particle.VelocityY = -30 - rand.Float64()*50
-		particle.Size = 2 + rand.Float64()*4
-		particle.Life = 3.0 + rand.Float64()*2.0
+		particle.VelocityX = (ps.rng.Particles.Float64() - 0.5) * 50
+		particle.VelocityY = -30 - ps.rng.Particles.Float64()*50
+		particle.Size = 2 + ps.rng.Particles.Float64()*4
+		particle.Life = 3.0 + ps.rng.Particles.Float64()*2.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{150, 255, 200, 255}
-		particle.RotationSpeed = (rand.Float64() - 0.5) * 5
+		particle.RotationSpeed = (ps.rng.Particles.Float64() - 0.5) * 5
 		particle.TrailLength = 5
 
 	case ParticleTypeStabilityWave:
-		particle.VelocityX = (rand.Float64() - 0.5) * 30
-		particle.VelocityY = (rand.Float64() - 0.5) * 30
-		particle.Size = 8 + rand.Float64()*12
-		particle.Life = 4.0 + rand.Float64()*2.0
+		particle.VelocityX = (ps.rng.Particles.Float64() - 0.5) * 30
+		particle.VelocityY = (ps.rng.Particles.Float64() - 0.5) * 30
+		particle.Size = 8 + ps.rng.Particles.Float64()*12
+		particle.Life = 4.0 + ps.rng.Particles.Float64()*2.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{100, 200, 255, 180}
-		particle.RotationSpeed = (rand.Float64() - 0.5) * 3
+		particle.RotationSpeed = (ps.rng.Particles.Float64() - 0.5) * 3
 
 	case ParticleTypeHarmonyOrb:
 		particle.VelocityX = 0
 		particle.VelocityY = 0
-		particle.Size = 10 + rand.Float64()*15
-		particle.Life = 5.0 + rand.Float64()*3.0
+		particle.Size = 10 + ps.rng.Particles.Float64()*15
+		particle.Life = 5.0 + ps.rng.Particles.Float64()*3.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{200, 255, 150, 200}
 		particle.RotationSpeed = 2.0
 		particle.TrailLength = 6
 
 	case ParticleTypeUnionBeam:
-		angle := rand.Float64() * math.Pi * 2
-		speed := 80 + rand.Float64()*120
+		angle := ps.rng.Particles.Float64() * math.Pi * 2
+		speed := 80 + ps.rng.Particles.Float64()*120
 		particle.VelocityX = math.Cos(angle) * speed
 		particle.VelocityY = math.Sin(angle) * speed
-		particle.Size = 3 + rand.Float64()*6
-		particle.Life = 4.0 + rand.Float64()*3.0
+		particle.Size = 3 + ps.rng.Particles.Float64()*6
+		particle.Life = 4.0 + ps.rng.Particles.Float64()*3.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{220, 200, 255, 150}
 		particle.IsAiming = true
@@ -340,13 +348,13 @@ func (ps *ParticleSystem) SpawnParticle(x, y float64, particleType ParticleType)
 		particle.TrailLength = 8
 
 	case ParticleTypeRealityRestore:
-		particle.VelocityX = (rand.Float64() - 0.5) * 40
-		particle.VelocityY = (rand.Float64() - 0.5) * 40
-		particle.Size = 6 + rand.Float64()*10
-		particle.Life = 6.0 + rand.Float64()*4.0
+		particle.VelocityX = (ps.rng.Particles.Float64() - 0.5) * 40
+		particle.VelocityY = (ps.rng.Particles.Float64() - 0.5) * 40
+		particle.Size = 6 + ps.rng.Particles.Float64()*10
+		particle.Life = 6.0 + ps.rng.Particles.Float64()*4.0
 		particle.MaxLife = particle.Life
 		particle.Color = color.RGBA{200, 230, 255, 120}
-		particle.RotationSpeed = (rand.Float64() - 0.5) * 4
+		particle.RotationSpeed = (ps.rng.Particles.Float64() - 0.5) * 4
 		particle.TrailLength = 10
 	}
 
@@ -374,18 +382,38 @@ func (ps *ParticleSystem) SpawnAimedParticle(x, y, targetX, targetY float64, par
 	}
 }
 
+// SpawnAfterimage leaves a fading copy of a sprite drawn at x, y with the
+// given scale.
+func (ps *ParticleSystem) SpawnAfterimage(x, y float64, sprite *ebiten.Image, scale float64, flipX bool) {
+	if sprite == nil || len(ps.Particles) >= ps.MaxParticles {
+		return
+	}
+
+	ps.Particles = append(ps.Particles, &Particle{
+		X:            x,
+		Y:            y,
+		Size:         scale,
+		Life:         0.25,
+		MaxLife:      0.25,
+		Color:        color.RGBA{120, 220, 255, 255},
+		ParticleType: ParticleTypeAfterimage,
+		Image:        sprite,
+		FlipX:        flipX,
+	})
+}
+
 func (ps *ParticleSystem) SpawnBurst(x, y float64, particleType ParticleType, count int) {
 	for i := 0; i < count; i++ {
-		offsetX := x + (rand.Float64()-0.5)*20
-		offsetY := y + (rand.Float64()-0.5)*20
+		offsetX := x + (ps.rng.Particles.Float64()-0.5)*20
+		offsetY := y + (ps.rng.Particles.Float64()-0.5)*20
 		ps.SpawnParticle(offsetX, offsetY, particleType)
 	}
 }
 
 func (ps *ParticleSystem) SpawnAmbientMadnessParticles() {
 	for i := 0; i < 3; i++ {
-		x := rand.Float64() * 1280
-		y := rand.Float64() * 720
+		x := ps.rng.Particles.Float64() * 1280
+		y := ps.rng.Particles.Float64() * 720
 
 		particleTypes := []ParticleType{
 			ParticleTypeMadness,
@@ -393,17 +421,17 @@ func (ps *ParticleSystem) SpawnAmbientMadnessParticles() {
 			ParticleTypeHallucinationSpark,
 		}
 
-		particleType := particleTypes[rand.Intn(len(particleTypes))]
+		particleType := particleTypes[ps.rng.Particles.Intn(len(particleTypes))]
 		ps.SpawnParticle(x, y, particleType)
 	}
 }
 
 func (ps *ParticleSystem) SpawnChildParticle(parent *Particle) {
 	child := &Particle{
-		X:            parent.X + (rand.Float64()-0.5)*20,
-		Y:            parent.Y + (rand.Float64()-0.5)*20,
-		VelocityX:    (rand.Float64() - 0.5) * 100,
-		VelocityY:    (rand.Float64() - 0.5) * 100,
+		X:            parent.X + (ps.rng.Particles.Float64()-0.5)*20,
+		Y:            parent.Y + (ps.rng.Particles.Float64()-0.5)*20,
+		VelocityX:    (ps.rng.Particles.Float64() - 0.5) * 100,
+		VelocityY:    (ps.rng.Particles.Float64() - 0.5) * 100,
 		Size:         parent.Size * 0.3,
 		Life:         parent.Life * 0.5,
 		MaxLife:      parent.Life * 0.5,
@@ -427,8 +455,8 @@ func (ps *ParticleSystem) drawParticle(screen *ebiten.Image, p *Particle, camera
 
 	if ps.MadnessLevel > 0.5 {
 		distortion := ps.MadnessLevel * 10
-		screenX += float32((rand.Float64() - 0.5) * distortion)
-		screenY += float32((rand.Float64() - 0.5) * distortion)
+		screenX += float32((ps.rng.Render.Float64() - 0.5) * distortion)
+		screenY += float32((ps.rng.Render.Float64() - 0.5) * distortion)
 	}
 
 	if len(p.TrailPositions) > 1 {
@@ -457,7 +485,7 @@ func (ps *ParticleSystem) drawParticle(screen *ebiten.Image, p *Particle, camera
 
 		glitchColor := color.RGBA{150, 150, 255, p.Alpha / 4}
 		for i := 0; i < 4; i++ {
-			offset := float32((rand.Float64() - 0.5) * 4)
+			offset := float32((ps.rng.Render.Float64() - 0.5) * 4)
 			vector.StrokeRect(screen, screenX-size/2+offset, screenY-size/2+offset, size, size, 1, glitchColor, false)
 		}
 
@@ -539,6 +567,18 @@ func (ps *ParticleSystem) drawParticle(screen *ebiten.Image, p *Particle, camera
 		crossColor := color.RGBA{180, 220, 255, p.Alpha / 2}
 		vector.StrokeLine(screen, screenX-crossSize, screenY, screenX+crossSize, screenY, 2, crossColor, false)
 		vector.StrokeLine(screen, screenX, screenY-crossSize, screenX, screenY+crossSize, 2, crossColor, false)
+
+	case ParticleTypeAfterimage:
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(p.Size, p.Size)
+		if p.FlipX {
+			op.GeoM.Scale(-1, 1)
+			op.GeoM.Translate(float64(p.Image.Bounds().Dx())*p.Size, 0)
+		}
+		op.GeoM.Translate(float64(screenX), float64(screenY))
+		alpha := float32(p.Alpha) / 255 * 0.6
+		op.ColorScale.Scale(float32(p.Color.R)/255*alpha, float32(p.Color.G)/255*alpha, float32(p.Color.B)/255*alpha, alpha)
+		screen.DrawImage(p.Image, op)
 	}
 }
 
@@ -570,8 +610,8 @@ func (ps *ParticleSystem) stabilizeNearbyParticles(x, y, radius float64) {
 
 func (ps *ParticleSystem) SpawnHealingAura(x, y float64) {
 	for i := 0; i < 3; i++ {
-		angle := rand.Float64() * math.Pi * 2
-		distance := 10 + rand.Float64()*20
+		angle := ps.rng.Particles.Float64() * math.Pi * 2
+		distance := 10 + ps.rng.Particles.Float64()*20
 
 		auraX := x + math.Cos(angle)*distance
 		auraY := y + math.Sin(angle)*distance
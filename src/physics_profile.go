@@ -0,0 +1,191 @@
+package src
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+
+	"github.com/temidaradev/ebijam25/assets"
+)
+
+// PhysicsProfile holds the movement and combat tuning of the player. The
+// default ships as assets/physics/default.json; a profile file on disk only
+// needs the fields it changes.
+//
+// Speeds are in pixels per second, accelerations in pixels per second
+// squared and times in seconds. Jump powers are upward speeds.
+type PhysicsProfile struct {
+	Gravity        float64 `json:"gravity"`
+	PlayerSpeed    float64 `json:"player_speed"`
+	MaxSpeed       float64 `json:"max_speed"`
+	Deceleration   float64 `json:"deceleration"`
+	JumpPower      float64 `json:"jump_power"`
+	JumpBufferTime float64 `json:"jump_buffer_time"`
+	CoyoteTime     float64 `json:"coyote_time"`
+	MinBounceSpeed float64 `json:"min_bounce_speed"`
+
+	RollDuration float64 `json:"roll_duration"`
+	RollSpeed    float64 `json:"roll_speed"`
+
+	WallJumpPower      float64 `json:"wall_jump_power"`
+	WallJumpHorizontal float64 `json:"wall_jump_horizontal"`
+	WallJumpTime       float64 `json:"wall_jump_time"`
+	WallSlideSpeed     float64 `json:"wall_slide_speed"`
+	WallClimbSpeed     float64 `json:"wall_climb_speed"`
+	WallGrabStamina    float64 `json:"wall_grab_stamina"`
+
+	LedgeClimbTime float64 `json:"ledge_climb_time"`
+	// LedgeRegrabTime keeps a player who let go of a ledge from catching it
+	// again on the way down.
+	LedgeRegrabTime float64 `json:"ledge_regrab_time"`
+
+	DashSpeed    float64 `json:"dash_speed"`
+	DashDuration float64 `json:"dash_duration"`
+	// DashCooldown is the least time between dashes; the dash also only
+	// comes back once the player is on the ground again.
+	DashCooldown   float64 `json:"dash_cooldown"`
+	DashInvulnTime float64 `json:"dash_invuln_time"`
+	// DashEndDamping is the share of dash speed kept when a dash ends.
+	DashEndDamping float64 `json:"dash_end_damping"`
+
+	InvulnerabilityTime float64 `json:"invulnerability_time"`
+
+	AttackDuration float64 `json:"attack_duration"`
+	AttackCooldown float64 `json:"attack_cooldown"`
+	AttackRange    float64 `json:"attack_range"`
+	AttackDamage   int     `json:"attack_damage"`
+	ComboWindow    float64 `json:"combo_window"`
+	MaxComboCount  int     `json:"max_combo_count"`
+}
+
+// DefaultPhysicsProfile returns the profile embedded in the game.
+func DefaultPhysicsProfile() PhysicsProfile {
+	var profile PhysicsProfile
+	if err := decodePhysicsProfile(assets.PhysicsProfileJSON, &profile); err != nil {
+		panic(fmt.Sprintf("physics: embedded default profile: %v", err))
+	}
+	return profile
+}
+
+// LoadPhysicsProfile reads a profile file and applies it over the default,
+// so fields missing from the file keep their default values. Unknown fields
+// are rejected to catch misspelled names, and so are values that Validate
+// rejects.
+func LoadPhysicsProfile(path string) (PhysicsProfile, error) {
+	profile := DefaultPhysicsProfile()
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return profile, err
+	}
+	if err := decodePhysicsProfile(encoded, &profile); err != nil {
+		return DefaultPhysicsProfile(), fmt.Errorf("physics: %s: %w", path, err)
+	}
+	if err := profile.Validate(); err != nil {
+		return DefaultPhysicsProfile(), fmt.Errorf("physics: %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// Validate reports the first field, by its JSON name, that the player can't
+// work with: speeds, durations and counts that must be above zero, delays
+// that can't be negative and a dash damping outside 0 to 1.
+func (profile PhysicsProfile) Validate() error {
+	type field struct {
+		name  string
+		value float64
+	}
+	positive := []field{
+		{"gravity", profile.Gravity},
+		{"player_speed", profile.PlayerSpeed},
+		{"max_speed", profile.MaxSpeed},
+		{"deceleration", profile.Deceleration},
+		{"jump_power", profile.JumpPower},
+		{"roll_duration", profile.RollDuration},
+		{"roll_speed", profile.RollSpeed},
+		{"wall_jump_power", profile.WallJumpPower},
+		{"wall_jump_horizontal", profile.WallJumpHorizontal},
+		{"wall_slide_speed", profile.WallSlideSpeed},
+		{"wall_climb_speed", profile.WallClimbSpeed},
+		{"wall_grab_stamina", profile.WallGrabStamina},
+		{"ledge_climb_time", profile.LedgeClimbTime},
+		{"dash_speed", profile.DashSpeed},
+		{"dash_duration", profile.DashDuration},
+		{"attack_duration", profile.AttackDuration},
+		{"attack_range", profile.AttackRange},
+		{"attack_damage", float64(profile.AttackDamage)},
+		{"combo_window", profile.ComboWindow},
+		{"max_combo_count", float64(profile.MaxComboCount)},
+	}
+	for _, f := range positive {
+		if f.value <= 0 {
+			return fmt.Errorf("%s must be above 0, got %v", f.name, f.value)
+		}
+	}
+
+	nonNegative := []field{
+		{"jump_buffer_time", profile.JumpBufferTime},
+		{"coyote_time", profile.CoyoteTime},
+		{"min_bounce_speed", profile.MinBounceSpeed},
+		{"wall_jump_time", profile.WallJumpTime},
+		{"ledge_regrab_time", profile.LedgeRegrabTime},
+		{"dash_cooldown", profile.DashCooldown},
+		{"dash_invuln_time", profile.DashInvulnTime},
+		{"invulnerability_time", profile.InvulnerabilityTime},
+		{"attack_cooldown", profile.AttackCooldown},
+	}
+	for _, f := range nonNegative {
+		if f.value < 0 {
+			return fmt.Errorf("%s can't be negative, got %v", f.name, f.value)
+		}
+	}
+
+	if profile.DashEndDamping < 0 || profile.DashEndDamping > 1 {
+		return fmt.Errorf("dash_end_damping must be between 0 and 1, got %v", profile.DashEndDamping)
+	}
+	return nil
+}
+
+// Hash fingerprints the profile so replays and saves can tell whether they
+// run with the tuning they were made with.
+func (profile PhysicsProfile) Hash() uint64 {
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		panic(fmt.Sprintf("physics: encoding profile: %v", err))
+	}
+	h := fnv.New64a()
+	h.Write(encoded)
+	return h.Sum64()
+}
+
+func decodePhysicsProfile(encoded []byte, profile *PhysicsProfile) error {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(profile)
+}
+
+// ApplyPhysicsProfile switches the player to the tuning of profile.
+func (p *Player) ApplyPhysicsProfile(profile PhysicsProfile) {
+	p.Physics = profile
+	p.Speed = profile.PlayerSpeed
+	p.MaxSpeed = profile.MaxSpeed
+	p.Deceleration = profile.Deceleration
+	p.JumpPower = -profile.JumpPower
+	p.JumpBufferTime = profile.JumpBufferTime
+	p.CoyoteTime = profile.CoyoteTime
+	p.DashSpeed = profile.DashSpeed
+	p.DashDuration = profile.DashDuration
+	p.AttackDamage = profile.AttackDamage
+	p.AttackRange = profile.AttackRange
+}
+
+// SetPhysicsProfile changes the player's tuning, e.g. to one loaded with
+// LoadPhysicsProfile.
+func (g *Game) SetPhysicsProfile(profile PhysicsProfile) {
+	g.player.ApplyPhysicsProfile(profile)
+}
+
+func (g *Game) PhysicsProfile() PhysicsProfile {
+	return g.player.Physics
+}
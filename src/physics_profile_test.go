@@ -0,0 +1,53 @@
+package src
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPhysicsProfileValidates(t *testing.T) {
+	if err := DefaultPhysicsProfile().Validate(); err != nil {
+		t.Fatalf("default profile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		field   string
+	}{
+		{"partial profile", `{"jump_power": 700}`, ""},
+		{"no grab stamina", `{"wall_grab_stamina": 0}`, "wall_grab_stamina"},
+		{"no dash duration", `{"dash_duration": 0}`, "dash_duration"},
+		{"no combo", `{"max_combo_count": 0}`, "max_combo_count"},
+		{"negative gravity", `{"gravity": -10}`, "gravity"},
+		{"negative cooldown", `{"dash_cooldown": -1}`, "dash_cooldown"},
+		{"zero cooldown", `{"dash_cooldown": 0}`, ""},
+		{"damping above 1", `{"dash_end_damping": 1.5}`, "dash_end_damping"},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "profile.json")
+			if err := os.WriteFile(path, []byte(tt.profile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			profile, err := LoadPhysicsProfile(path)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("rejected a valid profile: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.field) {
+				t.Fatalf("error = %v, want one naming %s", err, tt.field)
+			}
+			if profile != DefaultPhysicsProfile() {
+				t.Error("a rejected profile did not fall back to the default")
+			}
+		})
+	}
+}
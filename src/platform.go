@@ -0,0 +1,255 @@
+package src
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/ebijam25/assets"
+)
+
+const (
+	MovingPlatformObjectType = "platform"
+
+	// PlatformTriggerStand keeps a platform at its first stop until the
+	// player stands on it. It returns there and stops once left alone.
+	PlatformTriggerStand = "stand"
+
+	defaultPlatformSpeed = 60.0
+	defaultPlatformWait  = 0.5
+	// platformRideTolerance is how far above a platform a box may hover and
+	// still count as standing on it.
+	platformRideTolerance = 3.0
+)
+
+// MovingPlatform is a kinematic solid that follows a path. It ignores the
+// tiles and carries or pushes the player instead of being stopped by them.
+//
+// Platforms are rectangle objects of type "platform" with the properties:
+//
+//	path    object  polyline whose shape the platform follows, starting
+//	                from where the platform is placed
+//	speed   float   pixels per second (default 60)
+//	wait    float   seconds spent at each stop (default 0.5)
+//	loop    bool    go from the last stop back to the first instead of
+//	                reversing along the path
+//	trigger string  PlatformTriggerStand, or empty to always move
+type MovingPlatform struct {
+	ID             uint32
+	X, Y           float64
+	Width, Height  float64
+	Speed          float64
+	WaitTime       float64
+	Loop           bool
+	Trigger        string
+	DeltaX, DeltaY float64
+
+	stops     []assets.MapPoint
+	next      int
+	step      int
+	waitTimer float64
+	running   bool
+}
+
+func LoadMovingPlatforms(tileMap *assets.TileMap) []*MovingPlatform {
+	var platforms []*MovingPlatform
+	for _, object := range tileMap.ObjectsOfType(MovingPlatformObjectType) {
+		platform := &MovingPlatform{
+			ID:       object.ID,
+			X:        object.X,
+			Y:        object.Y,
+			Width:    object.Width,
+			Height:   object.Height,
+			Speed:    defaultPlatformSpeed,
+			WaitTime: defaultPlatformWait,
+			Loop:     object.Properties.GetBool("loop"),
+			Trigger:  object.Properties.GetString("trigger"),
+			stops:    []assets.MapPoint{{X: object.X, Y: object.Y}},
+		}
+		if object.HasProperty("speed") {
+			platform.Speed = object.Properties.GetFloat("speed")
+		}
+		if object.HasProperty("wait") {
+			platform.WaitTime = object.Properties.GetFloat("wait")
+		}
+
+		pathID, _ := object.ObjectRef("path")
+		if path, ok := tileMap.ObjectByID(pathID); ok && len(path.Points) > 1 {
+			origin := path.Points[0]
+			for _, point := range path.Points[1:] {
+				platform.stops = append(platform.stops, assets.MapPoint{
+					X: object.X + point.X - origin.X,
+					Y: object.Y + point.Y - origin.Y,
+				})
+			}
+		}
+		platform.Reset()
+		platforms = append(platforms, platform)
+	}
+	return platforms
+}
+
+// Reset puts the platform back at its first stop, waiting for its trigger
+// as it was when the level was loaded.
+func (mp *MovingPlatform) Reset() {
+	mp.X, mp.Y = mp.stops[0].X, mp.stops[0].Y
+	mp.DeltaX, mp.DeltaY = 0, 0
+	mp.next = 0
+	if len(mp.stops) > 1 {
+		mp.next = 1
+	}
+	mp.step = 1
+	mp.waitTimer = 0
+	mp.running = false
+}
+
+// Update moves the platform along its path and records the movement in
+// DeltaX and DeltaY. ridden tells whether the player stands on it.
+func (mp *MovingPlatform) Update(deltaTime float64, ridden bool) {
+	mp.DeltaX, mp.DeltaY = 0, 0
+	if len(mp.stops) < 2 || mp.Speed <= 0 {
+		return
+	}
+	if mp.Trigger == PlatformTriggerStand && !mp.running {
+		if !ridden {
+			return
+		}
+		mp.running = true
+	}
+	if mp.waitTimer > 0 {
+		mp.waitTimer -= deltaTime
+		return
+	}
+
+	target := mp.stops[mp.next]
+	dx, dy := target.X-mp.X, target.Y-mp.Y
+	distance := math.Hypot(dx, dy)
+	travel := mp.Speed * deltaTime
+	if travel < distance {
+		dx, dy = dx/distance*travel, dy/distance*travel
+	}
+	mp.X += dx
+	mp.Y += dy
+	mp.DeltaX, mp.DeltaY = dx, dy
+
+	if travel >= distance {
+		mp.arrive(ridden)
+	}
+}
+
+func (mp *MovingPlatform) arrive(ridden bool) {
+	mp.waitTimer = mp.WaitTime
+	if mp.Trigger == PlatformTriggerStand && mp.next == 0 && !ridden {
+		mp.running = false
+	}
+
+	if mp.Loop {
+		mp.next = (mp.next + 1) % len(mp.stops)
+		return
+	}
+	if mp.next+mp.step < 0 || mp.next+mp.step >= len(mp.stops) {
+		mp.step = -mp.step
+	}
+	mp.next += mp.step
+}
+
+func (mp *MovingPlatform) GetCollisionBox() CollisionBox {
+	return CollisionBox{X: mp.X, Y: mp.Y, Width: mp.Width, Height: mp.Height}
+}
+
+// Overlaps, Supports and pushOut take boxes by their drawn top-left corner;
+// CollisionSystem.platformBox converts the player's box.
+func (mp *MovingPlatform) Overlaps(box CollisionBox) bool {
+	return box.X < mp.X+mp.Width && box.X+box.Width > mp.X &&
+		box.Y < mp.Y+mp.Height && box.Y+box.Height > mp.Y
+}
+
+// Supports reports whether a box stands on top of the platform.
+func (mp *MovingPlatform) Supports(box CollisionBox) bool {
+	bottom := box.Y + box.Height
+	return box.X < mp.X+mp.Width && box.X+box.Width > mp.X &&
+		bottom >= mp.Y-platformRideTolerance && bottom <= mp.Y+platformRideTolerance
+}
+
+// pushOut returns the smallest move that takes a box the platform ran into
+// out of it in the direction the platform travelled. The box is swept
+// against the platform's last move, so a platform fast enough to pass
+// through it in one update still pushes it.
+func (mp *MovingPlatform) pushOut(box CollisionBox) (dx, dy float64) {
+	_, _, _, swept := assets.SweepAABB(box.X, box.Y, box.Width, box.Height, -mp.DeltaX, -mp.DeltaY,
+		mp.X-mp.DeltaX, mp.Y-mp.DeltaY, mp.Width, mp.Height)
+	if !swept && !mp.Overlaps(box) {
+		return 0, 0
+	}
+
+	type push struct {
+		dx, dy float64
+		along  bool
+	}
+	// Pushed boxes keep a skin away, so they aren't left overlapping the
+	// platform by a rounding error, which the next sweep would ignore.
+	const skin = assets.SweepSkin
+	pushes := []push{
+		{dx: mp.X - (box.X + box.Width) - skin, along: mp.DeltaX < 0},
+		{dx: mp.X + mp.Width - box.X + skin, along: mp.DeltaX > 0},
+		{dy: mp.Y - (box.Y + box.Height) - skin, along: mp.DeltaY < 0},
+		{dy: mp.Y + mp.Height - box.Y + skin, along: mp.DeltaY > 0},
+	}
+
+	best, found := push{}, false
+	for _, candidate := range pushes {
+		if !candidate.along {
+			continue
+		}
+		if !found || math.Abs(candidate.dx+candidate.dy) < math.Abs(best.dx+best.dy) {
+			best, found = candidate, true
+		}
+	}
+	return best.dx, best.dy
+}
+
+func (mp *MovingPlatform) Draw(screen *ebiten.Image, cameraX, cameraY float64) {
+	screenX := float32(mp.X - cameraX)
+	screenY := float32(mp.Y - cameraY)
+	width := float32(mp.Width)
+	height := float32(mp.Height)
+
+	vector.DrawFilledRect(screen, screenX, screenY, width, height, color.RGBA{150, 112, 74, 255}, false)
+	vector.DrawFilledRect(screen, screenX, screenY, width, 3, color.RGBA{214, 176, 120, 255}, false)
+	vector.StrokeRect(screen, screenX, screenY, width, height, 1, color.RGBA{90, 64, 40, 255}, false)
+}
+
+// updatePlatforms moves the platforms before the player, carrying the player
+// along when standing on one and pushing it aside when hit by one.
+func (g *Game) updatePlatforms(deltaTime float64) {
+	p := g.player
+	cs := p.CollisionSystem
+	if cs == nil {
+		return
+	}
+
+	for _, platform := range g.platforms {
+		ridden := p.OnGround && platform.Supports(cs.platformBox(p.GetCollisionBox()))
+		platform.Update(deltaTime, ridden)
+		if platform.DeltaX == 0 && platform.DeltaY == 0 {
+			continue
+		}
+
+		if ridden {
+			cs.UpdateGameObject(p, platform.DeltaX, platform.DeltaY)
+		}
+
+		dx, dy := platform.pushOut(cs.platformBox(p.GetCollisionBox()))
+		if dx == 0 && dy == 0 {
+			continue
+		}
+		cs.pushing = platform
+		cs.UpdateGameObject(p, dx, dy)
+		cs.pushing = nil
+		if dy < 0 && p.VelocityY > 0 {
+			p.VelocityY = 0
+			p.OnGround = true
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package src
+
+import (
+	"math"
+	"testing"
+
+	"github.com/temidaradev/ebijam25/assets"
+)
+
+func TestRespawnResetsPlatforms(t *testing.T) {
+	g := NewGame(1)
+	platform := &MovingPlatform{
+		X: 200, Y: 300, Width: 64, Height: 16,
+		Speed:    defaultPlatformSpeed,
+		WaitTime: defaultPlatformWait,
+		Trigger:  PlatformTriggerStand,
+		stops:    []assets.MapPoint{{X: 200, Y: 300}, {X: 400, Y: 300}, {X: 400, Y: 100}},
+	}
+	platform.Reset()
+	want := *platform
+	g.platforms = []*MovingPlatform{platform}
+
+	for i := 0; i < 4*DefaultTickRate; i++ {
+		platform.Update(1.0/DefaultTickRate, true)
+	}
+	if platform.X == want.X && platform.Y == want.Y {
+		t.Fatal("platform never moved")
+	}
+
+	g.player.Kill()
+	g.respawn()
+
+	if platform.X != want.X || platform.Y != want.Y || platform.next != want.next ||
+		platform.step != want.step || platform.waitTimer != want.waitTimer || platform.running != want.running {
+		t.Errorf("platform after respawn = %+v, want %+v", *platform, want)
+	}
+}
+
+// placePlayer moves the player so its box, as platforms see it, has its left
+// edge at left and its bottom at bottom.
+func placePlayer(g *Game, left, bottom float64) {
+	p := g.player
+	placed := p.CollisionSystem.platformBox(p.GetCollisionBox())
+	p.X += left - placed.X
+	p.Y += bottom - (placed.Y + placed.Height)
+	p.VelocityX, p.VelocityY = 0, 0
+
+	box := p.GetCollisionBox()
+	below := box
+	below.Y++
+	p.Ground, p.OnGround = p.CollisionSystem.Landing(box, below)
+}
+
+func TestMovingPlatforms(t *testing.T) {
+	// The desert is open above its floor between x=544 and x=800; the floor's
+	// drawn top is at y=384.
+	const floor = 384.0
+
+	newPlatform := func(x, y, width, height, speed float64, trigger string, stops ...assets.MapPoint) *MovingPlatform {
+		return &MovingPlatform{
+			X: x, Y: y, Width: width, Height: height,
+			Speed:   speed,
+			Trigger: trigger,
+			stops:   append([]assets.MapPoint{{X: x, Y: y}}, stops...),
+		}
+	}
+
+	tests := []struct {
+		name             string
+		platform         *MovingPlatform
+		playerX, playerY float64
+		ticks            int
+		check            func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform)
+	}{
+		{
+			name:     "rests on its top edge",
+			platform: newPlatform(560, 320, 96, 16, 0, ""),
+			playerX:  580, playerY: 300,
+			ticks: DefaultTickRate,
+			check: func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform) {
+				if !p.OnGround || math.Abs(box.Y+box.Height-platform.Y) > 0.05 {
+					t.Errorf("onGround=%v bottom=%v, want standing at y=%v", p.OnGround, box.Y+box.Height, platform.Y)
+				}
+			},
+		},
+		{
+			name:     "carries a rider sideways",
+			platform: newPlatform(560, 320, 96, 16, 60, "", assets.MapPoint{X: 700, Y: 320}),
+			playerX:  580, playerY: 320 - assets.SweepSkin,
+			ticks: DefaultTickRate,
+			check: func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform) {
+				if platform.X <= 600 {
+					t.Fatalf("platform only reached x=%v", platform.X)
+				}
+				if offset := box.X - platform.X; math.Abs(offset-20) > 0.5 || !p.OnGround {
+					t.Errorf("rider at offset %v onGround=%v, want to stay 20px in", offset, p.OnGround)
+				}
+			},
+		},
+		{
+			name:     "lifts a rider",
+			platform: newPlatform(560, 360, 96, 16, 60, "", assets.MapPoint{X: 560, Y: 200}),
+			playerX:  580, playerY: 360 - assets.SweepSkin,
+			ticks: DefaultTickRate,
+			check: func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform) {
+				if platform.Y >= 330 {
+					t.Fatalf("platform only reached y=%v", platform.Y)
+				}
+				if math.Abs(box.Y+box.Height-platform.Y) > 0.5 {
+					t.Errorf("rider bottom %v, platform top %v", box.Y+box.Height, platform.Y)
+				}
+			},
+		},
+		{
+			name:     "waits for a rider",
+			platform: newPlatform(560, 320, 96, 16, 60, PlatformTriggerStand, assets.MapPoint{X: 700, Y: 320}),
+			playerX:  740, playerY: floor - assets.SweepSkin,
+			ticks: DefaultTickRate,
+			check: func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform) {
+				if platform.X != 560 || platform.running {
+					t.Errorf("platform moved to x=%v with nobody on it", platform.X)
+				}
+			},
+		},
+		{
+			name:     "starts under a rider",
+			platform: newPlatform(560, 320, 96, 16, 60, PlatformTriggerStand, assets.MapPoint{X: 700, Y: 320}),
+			playerX:  580, playerY: 300,
+			ticks: DefaultTickRate,
+			check: func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform) {
+				if platform.X == 560 || !platform.running {
+					t.Error("platform did not start once stood on")
+				}
+			},
+		},
+		{
+			name:     "pushes along its travel",
+			platform: newPlatform(700, 320, 64, 48, 120, "", assets.MapPoint{X: 560, Y: 320}),
+			playerX:  660, playerY: floor - assets.SweepSkin,
+			ticks: DefaultTickRate,
+			check: func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform) {
+				if box.X+box.Width > platform.X+0.05 {
+					t.Errorf("player right edge %v inside platform at x=%v", box.X+box.Width, platform.X)
+				}
+				if box.X > 560 {
+					t.Errorf("player at x=%v was not pushed ahead of the platform", box.X)
+				}
+			},
+		},
+		{
+			name:     "fast platform does not tunnel",
+			platform: newPlatform(780, 320, 16, 48, 3000, "", assets.MapPoint{X: 450, Y: 320}),
+			playerX:  660, playerY: floor - assets.SweepSkin,
+			ticks: 10,
+			check: func(t *testing.T, p *Player, box CollisionBox, platform *MovingPlatform) {
+				if platform.X != 450 {
+					t.Fatalf("platform only reached x=%v", platform.X)
+				}
+				if box.X+box.Width > platform.X+0.05 {
+					t.Errorf("player right edge %v behind a platform that passed through it at x=%v", box.X+box.Width, platform.X)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := groundedGame(t)
+			tt.platform.WaitTime = 10
+			tt.platform.Reset()
+			g.platforms = []*MovingPlatform{tt.platform}
+			g.player.CollisionSystem.Platforms = g.platforms
+			placePlayer(g, tt.playerX, tt.playerY)
+
+			for range tt.ticks {
+				stepWith(t, g)
+			}
+			p := g.player
+			tt.check(t, p, p.CollisionSystem.platformBox(p.GetCollisionBox()), tt.platform)
+		})
+	}
+}
+
+func TestPlayerRestsOnDrawnTileTop(t *testing.T) {
+	g := groundedGame(t)
+	box := g.player.CollisionSystem.platformBox(g.player.GetCollisionBox())
+	bottom := box.Y + box.Height
+	if tileTop := math.Round(bottom/32) * 32; math.Abs(bottom-tileTop) > 0.05 {
+		t.Errorf("player bottom %v, want the top of a drawn tile", bottom)
+	}
+}
+
+// ledgePlatform puts a platform in the air with its top-left corner at 600,
+// 200 and finds the ledge a player rising along its left side would catch.
+func ledgePlatform(t *testing.T, g *Game) Ledge {
+	t.Helper()
+	cs := g.player.CollisionSystem
+	g.platforms = []*MovingPlatform{{X: 600, Y: 200, Width: 64, Height: 32}}
+	cs.Platforms = g.platforms
+
+	// The player's box with its top edge at top, given the way tile queries
+	// measure it.
+	size := g.player.GetCollisionBox()
+	left := 600 - size.Width - 0.5
+	at := func(top float64) CollisionBox {
+		half := float64(g.tileMap.TileWidth) / 2
+		return CollisionBox{X: left + size.Width/2 - half, Y: top + size.Height/2 - half, Width: size.Width, Height: size.Height}
+	}
+
+	ledge, ok := cs.FindLedge(at(210), at(190), 1)
+	if !ok {
+		t.Fatal("no ledge at the platform's corner")
+	}
+	return ledge
+}
+
+func TestLedgeOnPlatform(t *testing.T) {
+	g := groundedGame(t)
+	ledge := ledgePlatform(t, g)
+	if hang := g.player.CollisionSystem.platformBox(ledge.Hang); math.Abs(hang.Y-200) > 1 {
+		t.Errorf("hangs with its top at y=%v, want the platform top at 200", hang.Y)
+	}
+}
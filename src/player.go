@@ -5,7 +5,6 @@ import (
 	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/temidaradev/ebijam25/assets"
 )
 
@@ -22,6 +21,9 @@ type Player struct {
 
 	AnimationManager *assets.SimpleAnimationManager
 
+	// Physics is the tuning the player moves and fights with.
+	Physics PhysicsProfile
+
 	MaxSpeed       float64
 	Deceleration   float64
 	JumpBufferTime float64
@@ -39,9 +41,12 @@ type Player struct {
 	GroundLevel float64
 
 	Camera          *Camera
-	Controller      *ControllerInput
 	TileMap         *assets.TileMap
 	CollisionSystem *CollisionSystem
+	Events          *EventBus
+
+	// Ground is the surface the player stood on after the last physics step.
+	Ground assets.TileCollisionInfo
 
 	IsRolling bool
 	RollTimer float64
@@ -51,12 +56,17 @@ type Player struct {
 	OnWallLeft    bool
 	OnWallRight   bool
 
+	// CanDash unlocks the dash. DashReady is the charge a dash spends,
+	// restored on landing.
 	CanDash      bool
+	DashReady    bool
 	IsDashing    bool
 	DashTimer    float64
 	DashCooldown float64
 	DashSpeed    float64
 	DashDuration float64
+	DashDirX     float64
+	DashDirY     float64
 
 	HasDoubleJump  bool
 	DoubleJumpUsed bool
@@ -64,7 +74,10 @@ type Player struct {
 	Health      int
 	MaxHealth   int
 	InvulnTimer float64
-	IsDead      bool
+	// DashInvuln protects the player during a dash without playing the hurt
+	// animation that InvulnTimer does.
+	DashInvuln float64
+	IsDead     bool
 
 	IsAttacking    bool
 	AttackTimer    float64
@@ -76,8 +89,16 @@ type Player struct {
 	CanCombo       bool
 
 	IsWallClimbing bool
-	WallGrabTimer  float64
-	CanWallGrab    bool
+	// WallGrabTimer is how long the player has clung to walls and ledges
+	// since last landing. At WallGrabStamina they lose their grip.
+	WallGrabTimer float64
+	CanWallGrab   bool
+
+	IsHanging        bool
+	IsMantling       bool
+	MantleTimer      float64
+	Ledge            Ledge
+	ledgeRegrabTimer float64
 
 	IsMovingLeft  bool
 	IsMovingRight bool
@@ -98,28 +119,6 @@ type Player struct {
 	MadnessDamageTimer       float64
 }
 
-const (
-	WALL_JUMP_POWER      = -550.0
-	WALL_JUMP_HORIZONTAL = 320.0
-	WALL_SLIDE_SPEED     = 120.0
-	WALL_JUMP_TIME       = 0.15
-
-	DASH_SPEED    = 450.0
-	DASH_DURATION = 0.2
-
-	INVULNERABILITY_TIME = 1.0
-
-	ATTACK_DURATION      = 0.3
-	ATTACK_COOLDOWN_TIME = 0.4
-	ATTACK_RANGE         = 70.0
-	ATTACK_DAMAGE        = 1
-	COMBO_WINDOW         = 1.2
-	MAX_COMBO_COUNT      = 3
-
-	WALL_CLIMB_SPEED  = 200.0
-	WALL_GRAB_STAMINA = 3.0
-)
-
 func NewPlayer(x, y, worldWidth, worldHeight, groundLevel float64, tileMap *assets.TileMap) *Player {
 	animManager := assets.InitCharacterAnimations()
 
@@ -139,16 +138,10 @@ func NewPlayer(x, y, worldWidth, worldHeight, groundLevel float64, tileMap *asse
 		Y:                y,
 		VelocityX:        0,
 		VelocityY:        0,
-		Speed:            DefaultPlayerSpeed,
-		MaxSpeed:         DefaultMaxSpeed,
-		Deceleration:     DefaultDeceleration,
-		JumpPower:        -DefaultJumpPower,
 		OnGround:         false,
 		FacingRight:      true,
 		Scale:            1.8,
 		AnimationManager: animManager,
-		JumpBufferTime:   DefaultJumpBufferTime,
-		CoyoteTime:       DefaultCoyoteTime,
 		jumpBuffer:       0,
 		coyoteBuffer:     0,
 		groundBuffer:     0,
@@ -156,7 +149,6 @@ func NewPlayer(x, y, worldWidth, worldHeight, groundLevel float64, tileMap *asse
 		WorldHeight:      worldHeight,
 		GroundLevel:      groundLevel,
 		Camera:           NewCamera(DefaultScreenWidth, DefaultScreenHeight, cameraWorldW, cameraWorldH),
-		Controller:       NewControllerInput(),
 		TileMap:          tileMap,
 		CollisionSystem:  NewCollisionSystem(tileMap),
 
@@ -170,11 +162,10 @@ func NewPlayer(x, y, worldWidth, worldHeight, groundLevel float64, tileMap *asse
 		OnWallRight:   false,
 
 		CanDash:      true,
+		DashReady:    true,
 		IsDashing:    false,
 		DashTimer:    0,
 		DashCooldown: 0,
-		DashSpeed:    DASH_SPEED,
-		DashDuration: DASH_DURATION,
 
 		HasDoubleJump:  true,
 		DoubleJumpUsed: false,
@@ -186,8 +177,6 @@ func NewPlayer(x, y, worldWidth, worldHeight, groundLevel float64, tileMap *asse
 
 		IsAttacking:    false,
 		AttackTimer:    0,
-		AttackDamage:   ATTACK_DAMAGE,
-		AttackRange:    ATTACK_RANGE,
 		AttackCooldown: 0,
 		ComboCount:     0,
 		ComboTimer:     0,
@@ -217,18 +206,18 @@ func NewPlayer(x, y, worldWidth, worldHeight, groundLevel float64, tileMap *asse
 	}
 
 	player.Camera.VerticalOffset = verticalOffset
+	player.ApplyPhysicsProfile(DefaultPhysicsProfile())
 
 	return player
 }
 
-func (p *Player) Update(deltaTime float64) {
+func (p *Player) Update(deltaTime float64, input InputState) {
 	p.updateTimers(deltaTime)
 	p.updateSlowdown(deltaTime)
 
-	p.Controller.Update()
-
-	p.handleInput(deltaTime)
-	p.updatePhysics(deltaTime)
+	p.handleInput(deltaTime, input)
+	p.updatePhysics(deltaTime, input)
+	p.applyTileHazards()
 	p.updateAnimation()
 
 	p.updateEnvironmentalDamage(deltaTime)
@@ -267,6 +256,32 @@ func (p *Player) updateTimers(deltaTime float64) {
 		p.InvulnTimer -= deltaTime
 	}
 
+	if p.DashInvuln > 0 {
+		p.DashInvuln -= deltaTime
+	}
+
+	if p.DashTimer > 0 {
+		p.DashTimer -= deltaTime
+		if p.DashTimer <= 0 {
+			p.endDash()
+		}
+	}
+
+	if p.DashCooldown > 0 {
+		p.DashCooldown -= deltaTime
+	}
+
+	if p.MantleTimer > 0 {
+		p.MantleTimer -= deltaTime
+		if p.MantleTimer <= 0 {
+			p.finishMantle()
+		}
+	}
+
+	if p.ledgeRegrabTimer > 0 {
+		p.ledgeRegrabTimer -= deltaTime
+	}
+
 	if p.AttackTimer > 0 {
 		p.AttackTimer -= deltaTime
 		if p.AttackTimer <= 0 {
@@ -286,11 +301,10 @@ func (p *Player) updateTimers(deltaTime float64) {
 		}
 	}
 
-	if p.WallGrabTimer > 0 {
-		p.WallGrabTimer -= deltaTime
-		if p.WallGrabTimer <= 0 {
-			p.IsWallClimbing = false
-			p.CanWallGrab = false
+	if p.IsWallClimbing || p.IsHanging {
+		p.WallGrabTimer += deltaTime
+		if p.WallGrabTimer >= p.Physics.WallGrabStamina {
+			p.loseGrip()
 		}
 	}
 
@@ -302,36 +316,44 @@ func (p *Player) updateTimers(deltaTime float64) {
 	}
 }
 
-func (p *Player) handleInput(deltaTime float64) {
-	leftPressed := ebiten.IsKeyPressed(ebiten.KeyA) || ebiten.IsKeyPressed(ebiten.KeyArrowLeft)
-	rightPressed := ebiten.IsKeyPressed(ebiten.KeyD) || ebiten.IsKeyPressed(ebiten.KeyArrowRight)
-	jumpPressed := inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyW) ||
-		inpututil.IsKeyJustPressed(ebiten.KeyArrowUp)
-
-	controllerLeft := p.Controller.IsLeftPressed()
-	controllerRight := p.Controller.IsRightPressed()
-	controllerJump := p.Controller.IsJumpJustPressed()
-	horizontalAxis := p.Controller.GetHorizontalAxis()
+func (p *Player) handleInput(deltaTime float64, input InputState) {
+	leftPressed := input.IsPressed(ActionMoveLeft)
+	rightPressed := input.IsPressed(ActionMoveRight)
+	jumpPressed := input.IsJustPressed(ActionJump)
+	horizontalAxis := input.MoveAxis
 
-	rollPressed := inpututil.IsKeyJustPressed(ebiten.KeyShift) || inpututil.IsKeyJustPressed(ebiten.KeyZ) || p.Controller.IsRollJustPressed()
-	slideHeld := ebiten.IsKeyPressed(ebiten.KeyShift) || ebiten.IsKeyPressed(ebiten.KeyZ)
-	attackPressed := inpututil.IsKeyJustPressed(ebiten.KeyJ) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || p.Controller.IsAttackJustPressed()
+	rollPressed := input.IsJustPressed(ActionRoll)
+	slideHeld := input.IsPressed(ActionRoll)
+	attackPressed := input.IsJustPressed(ActionAttack)
+	dashPressed := input.IsJustPressed(ActionDash)
 
 	const deadZone = 0.2
 
-	p.IsMovingLeft = (leftPressed || controllerLeft)
-	p.IsMovingRight = (rightPressed || controllerRight)
+	p.IsMovingLeft = leftPressed
+	p.IsMovingRight = rightPressed
+
+	if p.IsHanging || p.IsMantling {
+		p.handleLedge(input)
+		return
+	}
 
 	landingDelay := p.OnGround && p.groundBuffer > 0
 	if attackPressed && !p.IsAttacking && p.AttackCooldown <= 0 && !p.IsRolling && !landingDelay {
 		p.performAttack()
 	}
 
+	if dashPressed && p.CanDash && p.DashReady && !p.IsDashing && p.DashCooldown <= 0 {
+		p.startDash(input)
+	}
+
+	if p.IsDashing {
+		return
+	}
+
 	if !p.IsRolling && rollPressed && p.OnGround {
 		p.IsRolling = true
-		p.RollTimer = RollDuration
-		slideSpeed := RollSpeed
+		p.RollTimer = p.Physics.RollDuration
+		slideSpeed := p.Physics.RollSpeed
 		if math.Abs(p.VelocityX) > slideSpeed {
 			slideSpeed = math.Abs(p.VelocityX)
 		}
@@ -345,7 +367,7 @@ func (p *Player) handleInput(deltaTime float64) {
 
 	if p.IsRolling {
 		if slideHeld && p.OnGround {
-			p.RollTimer = RollDuration * 0.6
+			p.RollTimer = p.Physics.RollDuration * 0.6
 		}
 
 		slideFriction := 0.95
@@ -353,20 +375,20 @@ func (p *Player) handleInput(deltaTime float64) {
 			p.VelocityX *= slideFriction
 		}
 
-		if (leftPressed || controllerLeft) && !(rightPressed || controllerRight) {
+		if leftPressed && !rightPressed {
 			if p.VelocityX > 0 {
 				p.VelocityX *= 0.8
 			}
-			if p.VelocityX > -RollSpeed*0.5 {
-				p.VelocityX = math.Max(p.VelocityX-RollSpeed*0.3, -RollSpeed)
+			if p.VelocityX > -p.Physics.RollSpeed*0.5 {
+				p.VelocityX = math.Max(p.VelocityX-p.Physics.RollSpeed*0.3, -p.Physics.RollSpeed)
 			}
 			p.FacingRight = false
-		} else if (rightPressed || controllerRight) && !(leftPressed || controllerLeft) {
+		} else if rightPressed && !leftPressed {
 			if p.VelocityX < 0 {
 				p.VelocityX *= 0.8
 			}
-			if p.VelocityX < RollSpeed*0.5 {
-				p.VelocityX = math.Min(p.VelocityX+RollSpeed*0.3, RollSpeed)
+			if p.VelocityX < p.Physics.RollSpeed*0.5 {
+				p.VelocityX = math.Min(p.VelocityX+p.Physics.RollSpeed*0.3, p.Physics.RollSpeed)
 			}
 			p.FacingRight = true
 		}
@@ -381,8 +403,8 @@ func (p *Player) handleInput(deltaTime float64) {
 
 	p.checkWallCollision()
 
-	if (leftPressed || controllerLeft) && !(rightPressed || controllerRight) {
-		if controllerLeft && !leftPressed && absFloat64(horizontalAxis) > deadZone {
+	if leftPressed && !rightPressed {
+		if absFloat64(horizontalAxis) > deadZone {
 			intensity := absFloat64(horizontalAxis)
 			if intensity > 1.0 {
 				intensity = 1.0
@@ -394,8 +416,8 @@ func (p *Player) handleInput(deltaTime float64) {
 			p.VelocityX = -corruptedSpeed
 		}
 		p.FacingRight = false
-	} else if (rightPressed || controllerRight) && !(leftPressed || controllerLeft) {
-		if controllerRight && !rightPressed && absFloat64(horizontalAxis) > deadZone {
+	} else if rightPressed && !leftPressed {
+		if absFloat64(horizontalAxis) > deadZone {
 			intensity := absFloat64(horizontalAxis)
 			if intensity > 1.0 {
 				intensity = 1.0
@@ -410,7 +432,7 @@ func (p *Player) handleInput(deltaTime float64) {
 	} else {
 		var decelAmount float64
 		if p.OnGround {
-			baseDecel := p.Deceleration * 2.8 * p.FrictionMultiplier
+			baseDecel := p.Deceleration * 2.8 * p.FrictionMultiplier * p.groundFriction()
 			speedFactor := math.Min(2.0, math.Abs(p.VelocityX)/150.0)
 			decelAmount = baseDecel * speedFactor * deltaTime
 
@@ -447,7 +469,7 @@ func (p *Player) handleInput(deltaTime float64) {
 		}
 	}
 
-	if jumpPressed || controllerJump {
+	if jumpPressed {
 		p.jumpBuffer = p.JumpBufferTime
 	}
 
@@ -456,20 +478,19 @@ func (p *Player) handleInput(deltaTime float64) {
 			if (p.OnWallLeft && p.IsMovingLeft) ||
 				(p.OnWallRight && p.IsMovingRight) {
 				p.IsWallClimbing = true
-				p.WallGrabTimer = WALL_GRAB_STAMINA
-				p.VelocityY = -WALL_CLIMB_SPEED
+				p.VelocityY = -p.Physics.WallClimbSpeed
 				p.jumpBuffer = 0
 				p.DoubleJumpUsed = false
 			} else {
 				if p.OnWallLeft {
-					p.VelocityX = WALL_JUMP_HORIZONTAL
+					p.VelocityX = p.Physics.WallJumpHorizontal
 					p.FacingRight = true
 				} else if p.OnWallRight {
-					p.VelocityX = -WALL_JUMP_HORIZONTAL
+					p.VelocityX = -p.Physics.WallJumpHorizontal
 					p.FacingRight = false
 				}
-				p.VelocityY = WALL_JUMP_POWER
-				p.WallJumpTimer = WALL_JUMP_TIME
+				p.VelocityY = -p.Physics.WallJumpPower
+				p.WallJumpTimer = p.Physics.WallJumpTime
 				p.jumpBuffer = 0
 				p.DoubleJumpUsed = false
 			}
@@ -491,34 +512,40 @@ func (p *Player) handleInput(deltaTime float64) {
 	}
 }
 
-func (p *Player) updatePhysics(deltaTime float64) {
+func (p *Player) updatePhysics(deltaTime float64, input InputState) {
+	if p.IsHanging || p.IsMantling {
+		p.VelocityX, p.VelocityY = 0, 0
+		p.OnGround = false
+		return
+	}
+
 	wasOnGround := p.OnGround
+	startBox := p.GetCollisionBox()
 
-	jumpHeld := ebiten.IsKeyPressed(ebiten.KeySpace) ||
-		ebiten.IsKeyPressed(ebiten.KeyW) ||
-		ebiten.IsKeyPressed(ebiten.KeyArrowUp) ||
-		p.Controller.IsJumpPressed()
+	jumpHeld := input.IsPressed(ActionJump)
 
-	if p.VelocityY < -100 && !jumpHeld {
+	if p.VelocityY < -100 && !jumpHeld && !p.IsDashing {
 		p.VelocityY *= 0.5
 	}
 
-	if !p.OnGround {
+	if p.IsDashing {
+		p.VelocityX = p.DashDirX * p.DashSpeed
+		p.VelocityY = p.DashDirY * p.DashSpeed
+	} else if !p.OnGround {
 		if p.IsWallClimbing {
 			if !((p.OnWallLeft && p.IsMovingLeft) ||
 				(p.OnWallRight && p.IsMovingRight)) {
 				p.IsWallClimbing = false
-				p.WallGrabTimer = 0
 			}
 		} else if (p.OnWallLeft || p.OnWallRight) && p.VelocityY > 0 {
-			corruptedGravity := Gravity * p.GravityMultiplier
+			corruptedGravity := p.Physics.Gravity * p.GravityMultiplier
 			p.VelocityY += corruptedGravity * deltaTime * 0.3
-			wallSlideSpeed := WALL_SLIDE_SPEED * p.FrictionMultiplier
+			wallSlideSpeed := p.Physics.WallSlideSpeed * p.FrictionMultiplier
 			if p.VelocityY > wallSlideSpeed {
 				p.VelocityY = wallSlideSpeed
 			}
 		} else {
-			corruptedGravity := Gravity * p.GravityMultiplier
+			corruptedGravity := p.Physics.Gravity * p.GravityMultiplier
 			p.VelocityY += corruptedGravity * deltaTime
 		}
 	}
@@ -549,14 +576,38 @@ func (p *Player) updatePhysics(deltaTime float64) {
 		finalX := targetX
 		finalY := targetY
 
-		if p.CollisionSystem.CheckCollisionAtPoint(horizontalBox) {
-			finalX = currentBox.X
-			p.VelocityX = 0
+		// Sweeps stop fast moves at the first contact; the destination checks
+		// catch boxes that start out overlapping something.
+		horizontal := p.CollisionSystem.Sweep(currentBox, deltaX, 0)
+		stepLift := 0.0
+		if horizontal.Hit || p.CollisionSystem.CheckCollisionAtPoint(horizontalBox) {
+			if lift, ok := p.CollisionSystem.StepUp(horizontalBox, math.Abs(deltaX)+SlopeSnapMargin); ok && wasOnGround {
+				stepLift = lift
+			} else {
+				finalX = currentBox.X
+				if horizontal.Hit {
+					finalX = horizontal.X
+				}
+				p.VelocityX = 0
+			}
 		}
 
-		if p.CollisionSystem.CheckCollisionAtPoint(verticalBox) {
-			finalY = currentBox.Y
+		vertical := p.CollisionSystem.Sweep(currentBox, 0, deltaY)
+		surface, hitVertical := vertical.Surface, vertical.Hit
+		restY := vertical.Y
+		if !hitVertical {
+			restY = currentBox.Y
+			if deltaY > 0 {
+				surface, hitVertical = p.CollisionSystem.Landing(currentBox, verticalBox)
+			} else {
+				hitVertical = p.CollisionSystem.CheckCollisionAtPoint(verticalBox)
+			}
+		}
+
+		if hitVertical {
+			finalY = restY
 			if p.VelocityY > 0 {
+				impactSpeed := p.VelocityY
 				p.VelocityY = 0
 				if !p.OnGround {
 					landingSpeed := math.Abs(p.VelocityY)
@@ -586,6 +637,11 @@ func (p *Player) updatePhysics(deltaTime float64) {
 					p.groundBuffer = 0.15
 				}
 				p.OnGround = true
+
+				if surface.Bounce > 0 && impactSpeed > p.Physics.MinBounceSpeed {
+					p.VelocityY = -impactSpeed * surface.Bounce
+					p.OnGround = false
+				}
 			} else if p.VelocityY < 0 {
 				p.VelocityY = 0
 				p.VelocityX *= 0.8
@@ -594,6 +650,12 @@ func (p *Player) updatePhysics(deltaTime float64) {
 			p.OnGround = false
 		}
 
+		if stepLift > 0 {
+			finalY = currentBox.Y - stepLift
+			p.VelocityY = 0
+			p.OnGround = true
+		}
+
 		p.SetPosition(finalX, finalY)
 
 		groundCheckBox := CollisionBox{
@@ -603,7 +665,20 @@ func (p *Player) updatePhysics(deltaTime float64) {
 			Height: currentBox.Height,
 		}
 
-		p.OnGround = p.CollisionSystem.CheckCollisionAtPoint(groundCheckBox)
+		finalBox := currentBox
+		finalBox.X, finalBox.Y = finalX, finalY
+		p.Ground, p.OnGround = p.CollisionSystem.Landing(finalBox, groundCheckBox)
+
+		// Walking down a slope moves the ground away a little every tick;
+		// follow it instead of falling in small hops.
+		if wasOnGround && p.VelocityY >= 0 {
+			if drop, ok := p.CollisionSystem.SnapToGround(finalBox, math.Abs(deltaX)+SlopeSnapMargin); ok && drop > 0 {
+				finalY += drop
+				p.SetPosition(finalX, finalY)
+				p.VelocityY = 0
+				p.OnGround = true
+			}
+		}
 	} else {
 		p.X += deltaX
 		p.Y += deltaY
@@ -617,6 +692,175 @@ func (p *Player) updatePhysics(deltaTime float64) {
 	if p.VelocityY < -10 {
 		p.OnGround = false
 	}
+
+	if p.OnGround && !p.IsDashing && p.DashCooldown <= 0 {
+		p.DashReady = true
+	}
+
+	p.tryGrabLedge(startBox)
+}
+
+// tryGrabLedge catches a ledge whose corner the top of the hitbox passed
+// this step, as long as the player falls or climbs while holding towards it.
+func (p *Player) tryGrabLedge(from CollisionBox) {
+	if p.CollisionSystem == nil || p.OnGround || p.IsDashing || p.ledgeRegrabTimer > 0 {
+		return
+	}
+	if p.WallGrabTimer >= p.Physics.WallGrabStamina {
+		return
+	}
+	if p.VelocityY < 0 && !p.IsWallClimbing {
+		return
+	}
+
+	dir := 0.0
+	if p.IsMovingRight && !p.IsMovingLeft {
+		dir = 1
+	} else if p.IsMovingLeft && !p.IsMovingRight {
+		dir = -1
+	}
+	ledge, ok := p.CollisionSystem.FindLedge(from, p.GetCollisionBox(), dir)
+	if !ok {
+		return
+	}
+
+	p.IsHanging = true
+	p.Ledge = ledge
+	p.SetPosition(ledge.Hang.X, ledge.Hang.Y)
+	p.VelocityX, p.VelocityY = 0, 0
+	p.FacingRight = dir > 0
+	p.IsWallClimbing = false
+	p.CanWallGrab = true
+	p.DoubleJumpUsed = false
+	p.jumpBuffer = 0
+}
+
+// handleLedge reads the input while hanging: up or jump climbs onto the
+// ledge, jump while holding away from the wall jumps off and down lets go.
+func (p *Player) handleLedge(input InputState) {
+	if p.IsMantling {
+		return
+	}
+	if _, ok := p.CollisionSystem.FindLedge(p.Ledge.Hang, p.Ledge.Hang, p.Ledge.Dir); !ok {
+		p.releaseLedge()
+		return
+	}
+
+	away := ActionMoveLeft
+	if p.Ledge.Dir < 0 {
+		away = ActionMoveRight
+	}
+
+	switch {
+	case input.IsJustPressed(ActionMoveDown):
+		p.releaseLedge()
+	case input.IsJustPressed(ActionJump) && input.IsPressed(away):
+		p.releaseLedge()
+		p.VelocityX = -p.Ledge.Dir * p.Physics.WallJumpHorizontal
+		p.VelocityY = -p.Physics.WallJumpPower
+		p.FacingRight = p.Ledge.Dir < 0
+		p.WallJumpTimer = p.Physics.WallJumpTime
+	case input.IsJustPressed(ActionJump) || input.IsJustPressed(ActionMoveUp):
+		p.IsHanging = false
+		p.IsMantling = true
+		p.MantleTimer = p.Physics.LedgeClimbTime
+	}
+}
+
+// finishMantle puts the player on top of the ledge once the climb is over,
+// or drops it if something took the space meanwhile.
+func (p *Player) finishMantle() {
+	stand := p.Ledge.Stand
+	if p.CollisionSystem == nil || p.CollisionSystem.CheckCollisionAtPoint(stand) {
+		p.releaseLedge()
+		return
+	}
+	p.IsMantling = false
+	p.MantleTimer = 0
+	p.SetPosition(stand.X, stand.Y)
+	p.VelocityX, p.VelocityY = 0, 0
+}
+
+// loseGrip drops the player from the wall or ledge they cling to once the
+// grab stamina is spent. It comes back on landing.
+func (p *Player) loseGrip() {
+	p.IsWallClimbing = false
+	p.CanWallGrab = false
+	if p.IsHanging {
+		p.releaseLedge()
+	}
+}
+
+func (p *Player) releaseLedge() {
+	p.IsHanging = false
+	p.IsMantling = false
+	p.MantleTimer = 0
+	p.ledgeRegrabTimer = p.Physics.LedgeRegrabTime
+}
+
+// ResetAbilities ends a dash, ledge hang or climb in progress, e.g. when
+// the player respawns.
+func (p *Player) ResetAbilities() {
+	p.IsDashing = false
+	p.DashTimer = 0
+	p.DashReady = true
+	p.DashInvuln = 0
+	p.IsHanging = false
+	p.IsMantling = false
+	p.MantleTimer = 0
+	p.WallGrabTimer = 0
+}
+
+// startDash launches the player in one of eight directions, taken from the
+// held directions or the facing when none are held.
+func (p *Player) startDash(input InputState) {
+	dirX, dirY := 0.0, 0.0
+	if input.IsPressed(ActionMoveLeft) {
+		dirX--
+	}
+	if input.IsPressed(ActionMoveRight) {
+		dirX++
+	}
+	if input.IsPressed(ActionMoveUp) {
+		dirY--
+	}
+	if input.IsPressed(ActionMoveDown) {
+		dirY++
+	}
+	if dirX == 0 && dirY == 0 {
+		dirX = 1
+		if !p.FacingRight {
+			dirX = -1
+		}
+	}
+	if dirX != 0 && dirY != 0 {
+		dirX, dirY = dirX*math.Sqrt2/2, dirY*math.Sqrt2/2
+	}
+	if dirX != 0 {
+		p.FacingRight = dirX > 0
+	}
+
+	p.IsDashing = true
+	p.DashReady = false
+	p.DashTimer = p.DashDuration
+	p.DashCooldown = p.Physics.DashCooldown
+	p.DashDirX, p.DashDirY = dirX, dirY
+	p.VelocityX, p.VelocityY = dirX*p.DashSpeed, dirY*p.DashSpeed
+	p.DashInvuln = p.Physics.DashInvulnTime
+
+	p.IsRolling = false
+	p.IsWallClimbing = false
+	p.jumpBuffer = 0
+	p.coyoteBuffer = 0
+}
+
+func (p *Player) endDash() {
+	p.IsDashing = false
+	p.DashTimer = 0
+	p.VelocityX *= p.Physics.DashEndDamping
+	if p.VelocityY < 0 {
+		p.VelocityY *= p.Physics.DashEndDamping
+	}
 }
 
 func (p *Player) updateAnimation() {
@@ -643,6 +887,21 @@ func (p *Player) updateAnimation() {
 			return
 		}
 
+		if p.IsMantling {
+			p.AnimationManager.SetAnimation("ledge-climb")
+			return
+		}
+
+		if p.IsHanging {
+			p.AnimationManager.SetAnimation("ledge-grab")
+			return
+		}
+
+		if p.IsDashing {
+			p.AnimationManager.SetAnimation("dash")
+			return
+		}
+
 		if p.InvulnTimer > 0 {
 			p.AnimationManager.SetAnimation("hurt")
 			return
@@ -887,24 +1146,58 @@ func (p *Player) checkWallCollision() {
 	p.OnWallRight = p.CollisionSystem.CheckCollisionAtPoint(rightBox) && !p.OnGround
 }
 
+// groundFriction is the friction of the surface underfoot; tiles without a
+// friction property count as 1.
+func (p *Player) groundFriction() float64 {
+	if !p.OnGround || p.Ground.Friction <= 0 {
+		return 1
+	}
+	return p.Ground.Friction
+}
+
+// applyTileHazards hurts the player while touching a hazard tile.
+func (p *Player) applyTileHazards() {
+	if p.CollisionSystem == nil || p.IsDead {
+		return
+	}
+
+	box := p.GetCollisionBox()
+	box.Height += 2
+
+	damage := 0
+	for _, info := range p.CollisionSystem.TileCollisions(box) {
+		damage = max(damage, info.Hazard)
+	}
+	if damage > 0 {
+		p.TakeDamage(damage)
+	}
+}
+
 func (p *Player) TakeDamage(damage int) {
-	if p.InvulnTimer > 0 || p.IsDead {
+	if p.IsInvulnerable() || p.IsDead {
 		return
 	}
 
 	p.Health -= damage
+	Publish(p.Events, PlayerDamaged{Amount: damage, Health: max(p.Health, 0)})
 	if p.Health <= 0 {
-		p.Health = 0
-		p.IsDead = true
-		p.VelocityX = 0
-		p.VelocityY = 0
+		p.Kill()
 	} else {
-		p.InvulnTimer = INVULNERABILITY_TIME
+		p.InvulnTimer = p.Physics.InvulnerabilityTime
 	}
 }
 
+// Kill ends the player's life outright, for deaths that aren't caused by
+// damage such as madness taking over.
+func (p *Player) Kill() {
+	p.Health = 0
+	p.IsDead = true
+	p.VelocityX = 0
+	p.VelocityY = 0
+}
+
 func (p *Player) IsInvulnerable() bool {
-	return p.InvulnTimer > 0
+	return p.InvulnTimer > 0 || p.DashInvuln > 0
 }
 
 func (p *Player) GetHealthPercentage() float64 {
@@ -913,19 +1206,19 @@ func (p *Player) GetHealthPercentage() float64 {
 
 func (p *Player) performAttack() {
 	p.IsAttacking = true
-	p.AttackTimer = ATTACK_DURATION
-	p.AttackCooldown = ATTACK_COOLDOWN_TIME
+	p.AttackTimer = p.Physics.AttackDuration
+	p.AttackCooldown = p.Physics.AttackCooldown
 
 	if p.ComboTimer > 0 && p.CanCombo {
 		p.ComboCount++
-		if p.ComboCount > MAX_COMBO_COUNT {
-			p.ComboCount = MAX_COMBO_COUNT
+		if p.ComboCount > p.Physics.MaxComboCount {
+			p.ComboCount = p.Physics.MaxComboCount
 		}
 	} else {
 		p.ComboCount = 1
 	}
 
-	p.ComboTimer = COMBO_WINDOW
+	p.ComboTimer = p.Physics.ComboWindow
 	p.CanCombo = true
 
 	if p.OnGround {
@@ -1013,7 +1306,11 @@ func absFloat64(x float64) float64 {
 }
 
 func (p *Player) UpdateCollisionSystem(tileMap *assets.TileMap) {
+	p.TileMap = tileMap
 	p.CollisionSystem = NewCollisionSystem(tileMap)
+	if tileMap != nil && p.Camera != nil {
+		p.Camera.SetWorldBounds(float64(tileMap.PixelWidth), float64(tileMap.PixelHeight))
+	}
 }
 
 func (p *Player) IsPlayerDead() bool {
@@ -1121,7 +1418,7 @@ func (p *Player) updatePhysicsMultipliers(deltaTime float64) {
 }
 
 func (p *Player) updateEnvironmentalDamage(deltaTime float64) {
-	if p.InvulnTimer > 0 || p.IsDead {
+	if p.IsInvulnerable() || p.IsDead {
 		return
 	}
 
@@ -1167,7 +1464,7 @@ func (p *Player) updateEnvironmentalDamage(deltaTime float64) {
 }
 
 func (p *Player) ApplyMadnessDamage(madnessLevel float64, deltaTime float64) {
-	if p.InvulnTimer > 0 || p.IsDead {
+	if p.IsInvulnerable() || p.IsDead {
 		return
 	}
 
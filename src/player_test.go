@@ -0,0 +1,175 @@
+package src
+
+import "testing"
+
+// groundedGame starts a run and lets the player land at the level start.
+func groundedGame(t *testing.T) *Game {
+	t.Helper()
+
+	g := NewGame(1)
+	g.scenes.Switch(NewPlayScene(g), NoTransition)
+	for range 2 * DefaultTickRate {
+		if err := g.Step(1.0/DefaultTickRate, InputState{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !g.player.OnGround {
+		t.Fatal("player never landed")
+	}
+	return g
+}
+
+func stepWith(t *testing.T, g *Game, actions ...Action) {
+	t.Helper()
+	if err := g.Step(1.0/DefaultTickRate, NewScriptedSource().Hold(1, actions...).Poll()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDashIsInvulnerableWithoutHurting(t *testing.T) {
+	g := groundedGame(t)
+	p := g.player
+
+	stepWith(t, g, ActionMoveRight, ActionDash)
+	if !p.IsDashing {
+		t.Fatal("dash did not start")
+	}
+	if p.InvulnTimer != 0 {
+		t.Errorf("dash set InvulnTimer to %v, which plays the hurt animation", p.InvulnTimer)
+	}
+	if !p.CanDash || p.DashReady {
+		t.Errorf("after dashing CanDash=%v DashReady=%v, want the unlock kept and the charge spent", p.CanDash, p.DashReady)
+	}
+
+	health := p.Health
+	p.TakeDamage(5)
+	if p.Health != health {
+		t.Errorf("took damage during a dash: health %d, want %d", p.Health, health)
+	}
+}
+
+func TestUpDashDoesNotJump(t *testing.T) {
+	keys := NewKeyboardSource().Keys
+	for _, up := range keys[ActionMoveUp] {
+		for _, jump := range keys[ActionJump] {
+			if up == jump {
+				t.Fatalf("key %v both aims up and jumps", up)
+			}
+		}
+	}
+
+	g := groundedGame(t)
+	p := g.player
+
+	// Up is held a tick before dash, the way players aim. With the dash on
+	// cooldown nothing may happen.
+	p.DashCooldown = 1
+	stepWith(t, g, ActionMoveUp)
+	stepWith(t, g, ActionMoveUp, ActionDash)
+	if p.IsDashing || p.VelocityY < 0 || !p.OnGround {
+		t.Fatalf("up, then up+dash on cooldown: dashing=%v velocityY=%v onGround=%v, want neither dash nor jump",
+			p.IsDashing, p.VelocityY, p.OnGround)
+	}
+
+	p.DashCooldown = 0
+	stepWith(t, g, ActionMoveUp)
+	stepWith(t, g, ActionMoveUp, ActionDash)
+	if !p.IsDashing || p.VelocityY >= 0 {
+		t.Fatalf("up, then up+dash: dashing=%v velocityY=%v, want an upward dash", p.IsDashing, p.VelocityY)
+	}
+	for i := 0; i < 3*DefaultTickRate && (p.IsDashing || !p.OnGround); i++ {
+		stepWith(t, g)
+	}
+
+	stepWith(t, g)
+	stepWith(t, g, ActionJump)
+	if p.VelocityY >= 0 {
+		t.Errorf("plain jump: velocityY=%v, want upward", p.VelocityY)
+	}
+}
+
+func TestGripRunsOutWhileHanging(t *testing.T) {
+	g := groundedGame(t)
+	p := g.player
+	p.OnGround = false
+	p.IsHanging = true
+
+	const dt = 1.0 / DefaultTickRate
+	for elapsed := 0.0; elapsed < p.Physics.WallGrabStamina-dt; elapsed += dt {
+		p.updateTimers(dt)
+	}
+	if !p.IsHanging {
+		t.Fatalf("let go after %.2fs, before the %.2fs of stamina ran out", p.WallGrabTimer, p.Physics.WallGrabStamina)
+	}
+
+	p.updateTimers(dt)
+	p.updateTimers(dt)
+	if p.IsHanging || p.CanWallGrab {
+		t.Fatalf("still hanging=%v canWallGrab=%v with no stamina left", p.IsHanging, p.CanWallGrab)
+	}
+	if p.ledgeRegrabTimer <= 0 {
+		t.Error("forced release does not keep the player from regrabbing")
+	}
+}
+
+func TestWallClimbDrainsStamina(t *testing.T) {
+	g := groundedGame(t)
+	p := g.player
+	p.OnGround = false
+	p.IsWallClimbing = true
+
+	const dt = 1.0 / DefaultTickRate
+	for elapsed := 0.0; elapsed <= p.Physics.WallGrabStamina+dt; elapsed += dt {
+		p.updateTimers(dt)
+	}
+	if p.IsWallClimbing || p.CanWallGrab {
+		t.Fatalf("still climbing=%v canWallGrab=%v with no stamina left", p.IsWallClimbing, p.CanWallGrab)
+	}
+
+	// Catching a ledge doesn't refill the grip; only landing does.
+	p.IsHanging = true
+	p.updateTimers(dt)
+	if p.IsHanging {
+		t.Error("hanging without stamina")
+	}
+}
+
+func TestGamepadLedgeInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		held     Action
+		hanging  bool
+		mantling bool
+	}{
+		{"down lets go", ActionMoveDown, false, false},
+		{"up climbs", ActionMoveUp, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := groundedGame(t)
+			p := g.player
+			ledge := ledgePlatform(t, g)
+			p.OnGround = false
+			p.IsHanging = true
+			p.Ledge = ledge
+			p.SetPosition(ledge.Hang.X, ledge.Hang.Y)
+
+			// A gamepad only reports the direction as held; GamepadSource
+			// turns the start of the hold into a press.
+			var pad heldEdges
+			for range 2 {
+				var state InputState
+				state.Held.Add(tt.held)
+				pad.press(&state, ActionMoveUp, ActionMoveDown)
+				if err := g.Step(1.0/DefaultTickRate, state); err != nil {
+					t.Fatal(err)
+				}
+				if p.IsHanging == tt.hanging && p.IsMantling == tt.mantling {
+					return
+				}
+			}
+			t.Errorf("hanging=%v mantling=%v, want %v %v", p.IsHanging, p.IsMantling, tt.hanging, tt.mantling)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package src
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	replayMagic   = "EJRP"
+	ReplayVersion = 2
+
+	replayAxisScale = 127
+)
+
+var (
+	ErrReplayMismatch = errors.New("replay: final state does not match recording")
+	ErrReplayPhysics  = errors.New("replay: recorded with a different physics profile")
+)
+
+// Replay is a recorded session: the seed, tick rate and physics profile it
+// ran with, the logical input of every tick, and a hash of the state it
+// ended in.
+//
+// File layout (little endian): magic, version u16, seed i64, tick rate u16,
+// physics profile hash u64, frame count u32, then runs of identical frames as (count, held,
+// justPressed) uvarints followed by the axis as one int8, and finally the
+// end state hash u64.
+type Replay struct {
+	Seed        int64
+	TickRate    int
+	PhysicsHash uint64
+	Frames      []InputState
+	FinalHash   uint64
+}
+
+// QuantizeInput rounds the analog axis to what a replay file can store, so
+// recorded and replayed sessions see the exact same values.
+func QuantizeInput(state InputState) InputState {
+	state.MoveAxis = float64(quantizeAxis(state.MoveAxis)) / replayAxisScale
+	return state
+}
+
+func quantizeAxis(axis float64) int8 {
+	return int8(math.Round(math.Max(-1, math.Min(1, axis)) * replayAxisScale))
+}
+
+func (r *Replay) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(replayMagic)
+	binary.Write(bw, binary.LittleEndian, uint16(ReplayVersion))
+	binary.Write(bw, binary.LittleEndian, r.Seed)
+	binary.Write(bw, binary.LittleEndian, uint16(r.TickRate))
+	binary.Write(bw, binary.LittleEndian, r.PhysicsHash)
+	binary.Write(bw, binary.LittleEndian, uint32(len(r.Frames)))
+
+	varint := make([]byte, binary.MaxVarintLen64)
+	writeUvarint := func(v uint64) {
+		n := binary.PutUvarint(varint, v)
+		bw.Write(varint[:n])
+	}
+
+	for i := 0; i < len(r.Frames); {
+		frame := QuantizeInput(r.Frames[i])
+		run := 1
+		for i+run < len(r.Frames) && QuantizeInput(r.Frames[i+run]) == frame {
+			run++
+		}
+		writeUvarint(uint64(run))
+		writeUvarint(uint64(frame.Held))
+		writeUvarint(uint64(frame.JustPressed))
+		bw.WriteByte(byte(quantizeAxis(frame.MoveAxis)))
+		i += run
+	}
+
+	binary.Write(bw, binary.LittleEndian, r.FinalHash)
+	return bw.Flush()
+}
+
+func ReadReplay(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("replay: reading header: %w", err)
+	}
+	if string(magic) != replayMagic {
+		return nil, errors.New("replay: not a replay file")
+	}
+
+	var header struct {
+		Version     uint16
+		Seed        int64
+		TickRate    uint16
+		PhysicsHash uint64
+		FrameCount  uint32
+	}
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("replay: reading header: %w", err)
+	}
+	if header.Version != ReplayVersion {
+		return nil, fmt.Errorf("replay: unsupported version %d", header.Version)
+	}
+
+	replay := &Replay{
+		Seed:        header.Seed,
+		TickRate:    int(header.TickRate),
+		PhysicsHash: header.PhysicsHash,
+		Frames:      make([]InputState, 0, header.FrameCount),
+	}
+
+	for uint32(len(replay.Frames)) < header.FrameCount {
+		run, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("replay: reading frames: %w", err)
+		}
+		held, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("replay: reading frames: %w", err)
+		}
+		justPressed, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("replay: reading frames: %w", err)
+		}
+		axis, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("replay: reading frames: %w", err)
+		}
+		if run == 0 || uint64(len(replay.Frames))+run > uint64(header.FrameCount) {
+			return nil, errors.New("replay: corrupt frame run")
+		}
+
+		frame := InputState{
+			Held:        ActionSet(held),
+			JustPressed: ActionSet(justPressed),
+			MoveAxis:    float64(int8(axis)) / replayAxisScale,
+		}
+		for i := uint64(0); i < run; i++ {
+			replay.Frames = append(replay.Frames, frame)
+		}
+	}
+
+	if err := binary.Read(br, binary.LittleEndian, &replay.FinalHash); err != nil {
+		return nil, fmt.Errorf("replay: reading final hash: %w", err)
+	}
+
+	return replay, nil
+}
+
+func (r *Replay) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadReplay(file)
+}
+
+// CheckPhysics reports whether g plays with the physics profile the replay
+// was recorded with; with any other tuning the input won't reproduce the run.
+func (r *Replay) CheckPhysics(g *Game) error {
+	if hash := g.PhysicsProfile().Hash(); hash != r.PhysicsHash {
+		return fmt.Errorf("%w: got %016x, want %016x", ErrReplayPhysics, hash, r.PhysicsHash)
+	}
+	return nil
+}
+
+// Verify reports whether the game ended in the state the recording did.
+func (r *Replay) Verify(g *Game) error {
+	if err := r.CheckPhysics(g); err != nil {
+		return err
+	}
+	if hash := g.StateHash(); hash != r.FinalHash {
+		return fmt.Errorf("%w: got %016x, want %016x", ErrReplayMismatch, hash, r.FinalHash)
+	}
+	return nil
+}
+
+// ReplayRecorder passes input through from another source and keeps every
+// polled state.
+type ReplayRecorder struct {
+	source InputSource
+	replay *Replay
+}
+
+func NewReplayRecorder(source InputSource, seed int64, tickRate int) *ReplayRecorder {
+	return &ReplayRecorder{
+		source: source,
+		replay: &Replay{Seed: seed, TickRate: tickRate},
+	}
+}
+
+func (r *ReplayRecorder) Poll() InputState {
+	state := QuantizeInput(r.source.Poll())
+	r.replay.Frames = append(r.replay.Frames, state)
+	return state
+}
+
+func (r *ReplayRecorder) Finish(g *Game) *Replay {
+	r.replay.PhysicsHash = g.PhysicsProfile().Hash()
+	r.replay.FinalHash = g.StateHash()
+	return r.replay
+}
+
+type ReplaySource struct {
+	frames []InputState
+	index  int
+}
+
+func NewReplaySource(replay *Replay) *ReplaySource {
+	return &ReplaySource{frames: replay.Frames}
+}
+
+func (r *ReplaySource) Poll() InputState {
+	if r.index >= len(r.frames) {
+		return InputState{}
+	}
+	state := r.frames[r.index]
+	r.index++
+	return state
+}
+
+func (r *ReplaySource) Done() bool {
+	return r.index >= len(r.frames)
+}
+
+// StateHash fingerprints the gameplay state that replays must reproduce.
+func (g *Game) StateHash() uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	writeFloat := func(v float64) {
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(v))
+		h.Write(buf)
+	}
+	writeInt := func(v int) {
+		binary.LittleEndian.PutUint64(buf, uint64(v))
+		h.Write(buf)
+	}
+	writeBool := func(v bool) {
+		if v {
+			writeInt(1)
+		} else {
+			writeInt(0)
+		}
+	}
+
+	writeInt(g.tickCount)
+	writeInt(int(g.GetState()))
+
+	p := g.player
+	writeFloat(p.X)
+	writeFloat(p.Y)
+	writeFloat(p.VelocityX)
+	writeFloat(p.VelocityY)
+	writeInt(p.Health)
+
+	writeFloat(g.madnessLevel)
+	writeFloat(g.worldStabilityLevel)
+	writeFloat(g.unionProgress)
+
+	writeInt(len(g.specialItems))
+	for _, item := range g.specialItems {
+		writeFloat(item.X)
+		writeFloat(item.Y)
+		writeInt(item.Health)
+		writeBool(item.Collected)
+		writeBool(item.IsActive)
+	}
+
+	return h.Sum64()
+}
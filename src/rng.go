@@ -0,0 +1,102 @@
+package src
+
+import "math/rand"
+
+const (
+	rngSaltWorld     = 0x5eed0001
+	rngSaltItems     = 0x5eed0002
+	rngSaltEffects   = 0x5eed0003
+	rngSaltParticles = 0x5eed0004
+	rngSaltRender    = 0x5eed0005
+)
+
+// RandomStreams splits one run seed into independent generators per
+// subsystem. World and Items drive gameplay and must only be drawn from
+// during Step; Effects and Particles are cosmetic but still tick-driven;
+// Render is used from Draw, whose call rate varies, and is never expected to
+// reproduce.
+type RandomStreams struct {
+	Seed      int64
+	World     *rand.Rand
+	Items     *rand.Rand
+	Effects   *rand.Rand
+	Particles *rand.Rand
+	Render    *rand.Rand
+
+	world, items, effects, particles *countingSource
+}
+
+// RandomState is how far each tick-driven stream has advanced from the seed.
+type RandomState struct {
+	World     uint64 `json:"world"`
+	Items     uint64 `json:"items"`
+	Effects   uint64 `json:"effects"`
+	Particles uint64 `json:"particles"`
+}
+
+func NewRandomStreams(seed int64) *RandomStreams {
+	r := &RandomStreams{
+		Seed:      seed,
+		world:     newCountingSource(seed, rngSaltWorld),
+		items:     newCountingSource(seed, rngSaltItems),
+		effects:   newCountingSource(seed, rngSaltEffects),
+		particles: newCountingSource(seed, rngSaltParticles),
+		Render:    rand.New(rand.NewSource(seed ^ rngSaltRender)),
+	}
+	r.World = rand.New(r.world)
+	r.Items = rand.New(r.items)
+	r.Effects = rand.New(r.effects)
+	r.Particles = rand.New(r.particles)
+	return r
+}
+
+func (r *RandomStreams) State() RandomState {
+	return RandomState{
+		World:     r.world.draws,
+		Items:     r.items.draws,
+		Effects:   r.effects.draws,
+		Particles: r.particles.draws,
+	}
+}
+
+// Restore reseeds every stream in place and advances the tick-driven ones to
+// state, so a resumed run continues the sequences it was saved with.
+func (r *RandomStreams) Restore(seed int64, state RandomState) {
+	*r = *NewRandomStreams(seed)
+	r.world.skip(state.World)
+	r.items.skip(state.Items)
+	r.effects.skip(state.Effects)
+	r.particles.skip(state.Particles)
+}
+
+// countingSource counts the values drawn from it. Every call advances the
+// underlying generator by exactly one step, so the count is its position.
+type countingSource struct {
+	source rand.Source64
+	draws  uint64
+}
+
+func newCountingSource(seed int64, salt int64) *countingSource {
+	return &countingSource{source: rand.NewSource(seed ^ salt).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.source.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.source.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.draws = 0
+	s.source.Seed(seed)
+}
+
+func (s *countingSource) skip(draws uint64) {
+	for s.draws < draws {
+		s.Uint64()
+	}
+}
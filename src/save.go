@@ -0,0 +1,325 @@
+package src
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	SaveVersion = 5
+
+	saveDirName  = "fight-for-union"
+	saveFileName = "save.json"
+)
+
+// saveMigrations upgrade raw save data from the keyed version to the next
+// one. Add an entry whenever SaveVersion is bumped.
+var saveMigrations = map[int]func(data map[string]any) error{
+	1: func(data map[string]any) error {
+		data["checkpoint_id"] = 0
+		return nil
+	},
+	2: func(data map[string]any) error {
+		// Level used to hold the environment name of the only map.
+		if data["level"] == "dust_of_divided_sun" {
+			data["level"] = "desert"
+		}
+		return nil
+	},
+	3: func(data map[string]any) error {
+		// An empty hash marks a save from before the profile was recorded.
+		data["physics_hash"] = ""
+		return nil
+	},
+	4: func(data map[string]any) error {
+		// Without stream positions the run resumes from the start of each
+		// stream, as it used to.
+		data["rng"] = RandomState{}
+		return nil
+	},
+}
+
+type SaveData struct {
+	Version int    `json:"version"`
+	Seed    int64  `json:"seed"`
+	Level   string `json:"level"`
+
+	// PhysicsHash is the hex Hash of the physics profile the save was made
+	// with. It is a string because migrations pass numbers through float64.
+	PhysicsHash string `json:"physics_hash"`
+
+	RNG RandomState `json:"rng"`
+
+	CheckpointID uint32 `json:"checkpoint_id"`
+
+	Player PlayerSave `json:"player"`
+	Items  []ItemSave `json:"items"`
+
+	MadnessLevel        float64 `json:"madness_level"`
+	WorldStabilityLevel float64 `json:"world_stability_level"`
+	UnionProgress       float64 `json:"union_progress"`
+	SurvivalTimer       float64 `json:"survival_timer"`
+	TotalItemsCollected int     `json:"total_items_collected"`
+}
+
+type PlayerSave struct {
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	FacingRight bool    `json:"facing_right"`
+	Health      int     `json:"health"`
+	MaxHealth   int     `json:"max_health"`
+
+	CanWallJump   bool `json:"can_wall_jump"`
+	CanWallGrab   bool `json:"can_wall_grab"`
+	CanDash       bool `json:"can_dash"`
+	HasDoubleJump bool `json:"has_double_jump"`
+}
+
+type ItemSave struct {
+	ObjectID  uint32          `json:"object_id,omitempty"`
+	Type      SpecialItemType `json:"type"`
+	X         float64         `json:"x"`
+	Y         float64         `json:"y"`
+	Health    int             `json:"health"`
+	Collected bool            `json:"collected"`
+	IsActive  bool            `json:"is_active"`
+}
+
+func DefaultSavePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, saveDirName, saveFileName), nil
+}
+
+func SaveExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func WriteSave(path string, data *SaveData) error {
+	data.Version = SaveVersion
+
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, encoded, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func ReadSave(path string) (*SaveData, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSave(encoded)
+}
+
+// DecodeSave parses a save of any known version, migrating it forward to
+// SaveVersion first.
+func DecodeSave(encoded []byte) (*SaveData, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		return nil, fmt.Errorf("save: %w", err)
+	}
+
+	versionValue, ok := raw["version"].(float64)
+	if !ok {
+		return nil, errors.New("save: missing version")
+	}
+	version := int(versionValue)
+	if version > SaveVersion {
+		return nil, fmt.Errorf("save: version %d is newer than supported version %d", version, SaveVersion)
+	}
+
+	for ; version < SaveVersion; version++ {
+		migrate, ok := saveMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("save: no migration from version %d", version)
+		}
+		if err := migrate(raw); err != nil {
+			return nil, fmt.Errorf("save: migrating from version %d: %w", version, err)
+		}
+		raw["version"] = version + 1
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("save: %w", err)
+	}
+
+	data := &SaveData{}
+	if err := json.Unmarshal(migrated, data); err != nil {
+		return nil, fmt.Errorf("save: %w", err)
+	}
+	return data, nil
+}
+
+func (g *Game) Snapshot() *SaveData {
+	p := g.player
+	data := &SaveData{
+		Version: SaveVersion,
+		Seed:    g.rng.Seed,
+		Level:   g.CurrentLevel().ID,
+
+		PhysicsHash: fmt.Sprintf("%016x", g.PhysicsProfile().Hash()),
+		RNG:         g.rng.State(),
+		Player: PlayerSave{
+			X:             p.X,
+			Y:             p.Y,
+			FacingRight:   p.FacingRight,
+			Health:        p.Health,
+			MaxHealth:     p.MaxHealth,
+			CanWallJump:   p.CanWallJump,
+			CanWallGrab:   p.CanWallGrab,
+			CanDash:       p.CanDash,
+			HasDoubleJump: p.HasDoubleJump,
+		},
+		MadnessLevel:        g.madnessLevel,
+		WorldStabilityLevel: g.worldStabilityLevel,
+		UnionProgress:       g.unionProgress,
+		SurvivalTimer:       g.survivalTimer,
+		TotalItemsCollected: g.totalItemsCollected,
+	}
+
+	if g.activeCheckpoint != nil {
+		data.CheckpointID = g.activeCheckpoint.ID
+	}
+
+	for _, item := range g.specialItems {
+		data.Items = append(data.Items, ItemSave{
+			ObjectID:  item.ObjectID,
+			Type:      item.ItemType,
+			X:         item.X,
+			Y:         item.Y,
+			Health:    item.Health,
+			Collected: item.Collected,
+			IsActive:  item.IsActive,
+		})
+	}
+
+	return data
+}
+
+// ApplySave restores a snapshot on top of a freshly restarted run.
+func (g *Game) ApplySave(data *SaveData) error {
+	if data.Level != "" && data.Level != g.CurrentLevel().ID {
+		if err := g.loadLevel(data.Level); err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+	}
+
+	if hash := fmt.Sprintf("%016x", g.PhysicsProfile().Hash()); data.PhysicsHash != "" && data.PhysicsHash != hash {
+		log.Printf("save: made with physics profile %s, playing with %s", data.PhysicsHash, hash)
+	}
+
+	g.restartGame()
+
+	p := g.player
+	p.X = data.Player.X
+	p.Y = data.Player.Y
+	p.FacingRight = data.Player.FacingRight
+	p.MaxHealth = data.Player.MaxHealth
+	p.Health = data.Player.Health
+	p.CanWallJump = data.Player.CanWallJump
+	p.CanWallGrab = data.Player.CanWallGrab
+	p.CanDash = data.Player.CanDash
+	p.HasDoubleJump = data.Player.HasDoubleJump
+	p.OnGround = false
+
+	if p.Camera != nil {
+		p.Camera.X = p.X - p.Camera.ViewportW/2
+		p.Camera.Y = 0
+		p.Camera.TargetX = p.Camera.X
+		p.Camera.TargetY = p.Camera.Y
+	}
+
+	mapItems := g.specialItems[:0]
+	for _, item := range g.specialItems {
+		if item.ItemType != ItemUnionCrystal {
+			mapItems = append(mapItems, item)
+		}
+	}
+	g.specialItems = mapItems
+
+	itemsByObjectID := make(map[uint32]*SpecialItem)
+	for _, item := range g.specialItems {
+		if item.ObjectID != 0 {
+			itemsByObjectID[item.ObjectID] = item
+		}
+	}
+
+	// Items are matched by map object ID; saves written before items came
+	// from the map have none and fall back to list order.
+	for i, saved := range data.Items {
+		var item *SpecialItem
+		if saved.Type == ItemUnionCrystal {
+			item = g.spawnUnionCrystal()
+		} else if saved.ObjectID != 0 {
+			item = itemsByObjectID[saved.ObjectID]
+		} else if i < len(g.specialItems) {
+			item = g.specialItems[i]
+		}
+		if item == nil || item.ItemType != saved.Type {
+			log.Printf("save: dropping item %d of type %d with no matching map item", saved.ObjectID, saved.Type)
+			continue
+		}
+		item.X = saved.X
+		item.Y = saved.Y
+		item.Health = saved.Health
+		item.Collected = saved.Collected
+		item.IsActive = saved.IsActive
+		if item.Collected {
+			g.collectedItems[item.ItemType] = true
+		}
+	}
+
+	g.madnessLevel = data.MadnessLevel
+	g.worldStabilityLevel = data.WorldStabilityLevel
+	g.unionProgress = data.UnionProgress
+	g.survivalTimer = data.SurvivalTimer
+	g.totalItemsCollected = data.TotalItemsCollected
+
+	if checkpoint := g.findCheckpoint(data.CheckpointID); checkpoint != nil {
+		checkpoint.Active = true
+		checkpoint.snapshot = data
+		g.activeCheckpoint = checkpoint
+	}
+
+	// The streams are shared with the menu and particle systems, so they are
+	// restored in place, after the items above are rebuilt.
+	g.rng.Restore(data.Seed, data.RNG)
+
+	return nil
+}
+
+func (g *Game) SaveGame(path string) error {
+	if path == "" {
+		return errors.New("save: no save location")
+	}
+	return WriteSave(path, g.Snapshot())
+}
+
+func (g *Game) LoadGame(path string) error {
+	data, err := ReadSave(path)
+	if err != nil {
+		return err
+	}
+	return g.ApplySave(data)
+}
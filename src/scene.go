@@ -0,0 +1,207 @@
+package src
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Scene is one screen of the game. Only the top scene of the stack is
+// updated. OnEnter and OnExit run when a scene is added to or removed from
+// the stack, not when another scene is pushed on top of it.
+type Scene interface {
+	OnEnter()
+	OnExit()
+	Update(deltaTime float64, input InputState) error
+	Draw(screen *ebiten.Image)
+}
+
+// OverlayScene is a scene drawn on top of the one beneath it instead of
+// replacing it, such as the pause menu over gameplay.
+type OverlayScene interface {
+	Scene
+	IsOverlay() bool
+}
+
+type TransitionKind int
+
+const (
+	TransitionNone TransitionKind = iota
+	TransitionFade
+	TransitionWipe
+)
+
+// Transition covers the screen for the first half of Duration, applies the
+// scene change at full cover and reveals the result in the second half.
+type Transition struct {
+	Kind     TransitionKind
+	Duration float64
+}
+
+var (
+	NoTransition   = Transition{}
+	FadeTransition = Transition{Kind: TransitionFade, Duration: 1.0}
+	WipeTransition = Transition{Kind: TransitionWipe, Duration: 0.8}
+)
+
+type SceneManager struct {
+	stack []Scene
+
+	transition      Transition
+	transitionTimer float64
+	pending         func()
+}
+
+func NewSceneManager() *SceneManager {
+	return &SceneManager{}
+}
+
+func (sm *SceneManager) Top() Scene {
+	if len(sm.stack) == 0 {
+		return nil
+	}
+	return sm.stack[len(sm.stack)-1]
+}
+
+func (sm *SceneManager) Len() int {
+	return len(sm.stack)
+}
+
+// InTransition reports whether a fade or wipe is running.
+func (sm *SceneManager) InTransition() bool {
+	return sm.transition.Kind != TransitionNone
+}
+
+// Run applies change behind the given transition. Changes without a
+// transition apply immediately; others are dropped while a transition is
+// already running, and Run reports whether change was accepted.
+func (sm *SceneManager) Run(transition Transition, change func()) bool {
+	if transition.Kind == TransitionNone || transition.Duration <= 0 {
+		change()
+		return true
+	}
+	if sm.InTransition() {
+		return false
+	}
+
+	sm.transition = transition
+	sm.transitionTimer = 0
+	sm.pending = change
+	return true
+}
+
+func (sm *SceneManager) Push(scene Scene, transition Transition) bool {
+	return sm.Run(transition, func() { sm.push(scene) })
+}
+
+func (sm *SceneManager) Pop(transition Transition) bool {
+	return sm.Run(transition, sm.pop)
+}
+
+// Replace swaps the top scene for scene.
+func (sm *SceneManager) Replace(scene Scene, transition Transition) bool {
+	return sm.Run(transition, func() {
+		sm.pop()
+		sm.push(scene)
+	})
+}
+
+// Switch clears the whole stack and starts over with scene.
+func (sm *SceneManager) Switch(scene Scene, transition Transition) bool {
+	return sm.Run(transition, func() {
+		for len(sm.stack) > 0 {
+			sm.pop()
+		}
+		sm.push(scene)
+	})
+}
+
+func (sm *SceneManager) push(scene Scene) {
+	sm.stack = append(sm.stack, scene)
+	scene.OnEnter()
+}
+
+func (sm *SceneManager) pop() {
+	top := sm.Top()
+	if top == nil {
+		return
+	}
+	sm.stack[len(sm.stack)-1] = nil
+	sm.stack = sm.stack[:len(sm.stack)-1]
+	top.OnExit()
+}
+
+// Update advances a running transition and the top scene. The scenes are
+// frozen while the screen is being covered and resume during the reveal.
+func (sm *SceneManager) Update(deltaTime float64, input InputState) error {
+	if sm.InTransition() {
+		sm.transitionTimer += deltaTime
+		if sm.pending != nil {
+			if sm.transitionTimer < sm.transition.Duration/2 {
+				return nil
+			}
+			change := sm.pending
+			sm.pending = nil
+			change()
+		}
+		if sm.transitionTimer >= sm.transition.Duration {
+			sm.transition = NoTransition
+			sm.transitionTimer = 0
+		}
+	}
+
+	top := sm.Top()
+	if top == nil {
+		return nil
+	}
+	return top.Update(deltaTime, input)
+}
+
+// Draw draws the top scene along with every overlay beneath it down to the
+// first opaque scene, then the transition cover.
+func (sm *SceneManager) Draw(screen *ebiten.Image) {
+	first := len(sm.stack) - 1
+	for first > 0 {
+		overlay, ok := sm.stack[first].(OverlayScene)
+		if !ok || !overlay.IsOverlay() {
+			break
+		}
+		first--
+	}
+	for i := max(first, 0); i < len(sm.stack); i++ {
+		sm.stack[i].Draw(screen)
+	}
+
+	sm.drawTransition(screen)
+}
+
+func (sm *SceneManager) drawTransition(screen *ebiten.Image) {
+	if !sm.InTransition() {
+		return
+	}
+
+	half := sm.transition.Duration / 2
+	covering := sm.pending != nil
+	progress := sm.transitionTimer / half
+	if !covering {
+		progress = 2 - progress
+	}
+	progress = max(0, min(1, progress))
+
+	bounds := screen.Bounds()
+	width, height := float32(bounds.Dx()), float32(bounds.Dy())
+
+	switch sm.transition.Kind {
+	case TransitionFade:
+		vector.DrawFilledRect(screen, 0, 0, width, height, color.RGBA{0, 0, 0, uint8(progress * 255)}, false)
+	case TransitionWipe:
+		// Sweeps in from the left and out to the right.
+		cover := width * float32(progress)
+		x := float32(0)
+		if !covering {
+			x = width - cover
+		}
+		vector.DrawFilledRect(screen, x, 0, cover, height, color.Black, false)
+	}
+}
@@ -0,0 +1,508 @@
+package src
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/ebijam25/assets"
+	"github.com/temidaradev/esset/v2"
+)
+
+type MenuScene struct {
+	game *Game
+}
+
+func NewMenuScene(g *Game) *MenuScene {
+	return &MenuScene{game: g}
+}
+
+func (s *MenuScene) OnEnter() {
+	s.game.menu.SetMainState()
+}
+
+func (s *MenuScene) OnExit() {}
+
+func (s *MenuScene) Update(deltaTime float64, input InputState) error {
+	g := s.game
+	if err := g.menu.Update(deltaTime, input); err != nil {
+		return err
+	}
+
+	if g.menu.IsStartSelected() {
+		g.scenes.Switch(NewPlayScene(g), FadeTransition)
+	}
+
+	if g.menu.IsLoadRequested() {
+		if err := g.LoadGame(g.savePath); err != nil {
+			log.Printf("Failed to load save: %v", err)
+		} else {
+			g.scenes.Switch(NewPlayScene(g), FadeTransition)
+		}
+	}
+
+	if g.menu.IsExitSelected() {
+		return ebiten.Termination
+	}
+
+	return nil
+}
+
+func (s *MenuScene) Draw(screen *ebiten.Image) {
+	g := s.game
+	layers := assets.GetLayersByEnvironment(g.currentEnvironment)
+	assets.DrawBackgroundLayers(screen, layers, g.parallaxOffset*0.1, 0, screen.Bounds().Dx())
+	g.menu.Draw(screen)
+}
+
+// PlayScene runs the level. Pause, death and the ending are overlays pushed
+// on top of it, so the level keeps its state while they are shown.
+type PlayScene struct {
+	game *Game
+}
+
+func NewPlayScene(g *Game) *PlayScene {
+	return &PlayScene{game: g}
+}
+
+func (s *PlayScene) OnEnter() {}
+
+func (s *PlayScene) OnExit() {}
+
+func (s *PlayScene) Update(deltaTime float64, input InputState) error {
+	g := s.game
+
+	if input.IsJustPressed(ActionToggleDebug) {
+		g.showCollisionBoxes = !g.showCollisionBoxes
+	}
+
+	if input.IsJustPressed(ActionResetPosition) {
+		g.player.ResetToSafePosition()
+	}
+
+	g.updateSchizophrenicEffects(deltaTime)
+
+	g.updateChaosAtmosphere(deltaTime)
+
+	g.globalParticleSystem.Update(deltaTime, g.madnessLevel)
+	g.madnessParticleSystem.Update(deltaTime, g.madnessLevel)
+
+	g.chaosIntensityLevel = g.madnessLevel * (.5 + 0.3*math.Sin(g.realityGlitchTimer*7.0))
+
+	for _, item := range g.specialItems {
+		item.Update(deltaTime)
+
+		if g.player.IsPerformingAttack() {
+			attackX, attackY, attackW, attackH := g.player.GetAttackBox()
+			if item.CheckHitCollision(attackX, attackY, attackW, attackH) {
+				if item.TakeHit() {
+					Publish(g.events, ItemCollected{Item: item})
+				} else {
+					Publish(g.events, ItemHit{Item: item})
+				}
+			}
+		}
+	}
+
+	madnessMultiplier := 1.0 + g.madnessLevel*3.0
+	chaosOffset := math.Sin(math.Floor(g.simulationTime)) * 2.0 * g.madnessLevel
+	g.parallaxOffset += (0.5 + chaosOffset) * madnessMultiplier
+
+	g.player.UpdatePhysicsCorruption(g.specialItems, deltaTime)
+
+	g.player.ApplyMadnessDamage(g.madnessLevel, deltaTime)
+
+	g.checkProximityDamage(deltaTime)
+
+	g.updateDifficultyAndPressure(deltaTime)
+
+	g.updatePlatforms(deltaTime)
+
+	g.player.Update(deltaTime, input)
+	g.updateDashTrail(deltaTime)
+
+	if g.tileMap != nil {
+		x, y, width, height := g.player.GetBounds()
+		cameraX, cameraY := g.player.GetCamera().GetView()
+		g.tileMap.SetFocus(x, y, width, height, cameraX, cameraY)
+		g.tileMap.Update(deltaTime)
+	}
+
+	g.updateCheckpoints(deltaTime)
+
+	g.checkExitZones()
+
+	// Collecting the union crystal pushes the ending over this scene.
+	if g.scenes.Top() != s {
+		return nil
+	}
+
+	g.checkMadnessThresholds()
+
+	cause := DeathByDamage
+	if g.madnessLevel >= 1.0 {
+		g.player.Kill()
+		cause = DeathByMadness
+	} else if g.player.Y >= 1000 {
+		cause = DeathByFalling
+	}
+
+	if g.madnessLevel >= 1.0 || g.player.Y >= 1000 || g.player.IsPlayerDead() {
+		Publish(g.events, PlayerDied{Cause: cause, X: g.player.X, Y: g.player.Y})
+		g.scenes.Push(NewDeathScene(g), NoTransition)
+		return nil
+	}
+
+	if input.IsJustPressed(ActionPause) {
+		g.scenes.Push(NewPauseScene(g), NoTransition)
+	}
+
+	return nil
+}
+
+// drawWorld draws the level and everything in it as seen from cameraX,
+// cameraY, with the backgrounds scrolled to backgroundX, backgroundY.
+func (g *Game) drawWorld(screen *ebiten.Image, camera *Camera, cameraX, cameraY, backgroundX, backgroundY float64) {
+	assets.DrawBackgroundLayers(screen, g.backgroundLayers(), backgroundX, backgroundY, screen.Bounds().Dx())
+
+	if g.tileMap != nil {
+		g.tileMap.Draw(screen, cameraX, cameraY)
+	}
+
+	for _, platform := range g.platforms {
+		platform.Draw(screen, cameraX, cameraY)
+	}
+
+	for _, checkpoint := range g.checkpoints {
+		checkpoint.Draw(screen, cameraX, cameraY)
+	}
+
+	for _, item := range g.specialItems {
+		item.Draw(screen, cameraX, cameraY)
+	}
+
+	g.globalParticleSystem.Draw(screen, cameraX, cameraY)
+	g.madnessParticleSystem.Draw(screen, cameraX, cameraY)
+
+	g.drawPlayerWithCamera(screen, camera)
+
+	if g.tileMap != nil {
+		g.tileMap.DrawForeground(screen, cameraX, cameraY)
+	}
+}
+
+func (s *PlayScene) Draw(screen *ebiten.Image) {
+	g := s.game
+	screenWidth, screenHeight := screen.Bounds().Dx(), screen.Bounds().Dy()
+
+	camera := g.player.GetCamera()
+	cameraX, cameraY := camera.GetView()
+
+	cameraX += g.screenShakeX
+	cameraY += g.screenShakeY
+
+	backgroundX := cameraX + g.screenDistortionX*0.1
+	backgroundY := cameraY + g.screenDistortionY*0.1
+	if g.isRealityBroken || g.chaosAtmosphereLevel > 0.7 {
+		glitchOffset := g.parallaxOffset * (1.0 + g.rng.Render.Float64()*0.5)
+		atmosphereOffset := g.chaosAtmosphereLevel * 3.0 * math.Sin(g.realityGlitchTimer*6.0)
+		backgroundX = cameraX + glitchOffset + g.screenDistortionX*0.2 + atmosphereOffset
+		backgroundY = cameraY + glitchOffset + g.screenDistortionY*0.2 + atmosphereOffset*0.2
+	}
+
+	g.drawWorld(screen, camera, cameraX, cameraY, backgroundX, backgroundY)
+
+	if g.colorShiftIntensity > 0.01 {
+		limitedIntensity := math.Min(g.colorShiftIntensity, 0.2)
+		alpha := uint8(math.Min(16, 16*limitedIntensity))
+		overlayColor := color.RGBA{
+			uint8(120 * math.Sin(g.realityGlitchTimer*3.0)),
+			uint8(120 * math.Sin(g.realityGlitchTimer*2.0)),
+			uint8(120 * math.Sin(g.realityGlitchTimer*4.0)),
+			alpha,
+		}
+		vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), overlayColor, false)
+	}
+
+	if g.madnessLevel >= 0.8 {
+		criticalIntensity := 0.2
+		pulseIntensity := 5.
+
+		criticalOverlay := color.RGBA{
+			255,
+			0,
+			0,
+			uint8(10 * criticalIntensity * pulseIntensity),
+		}
+		vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), criticalOverlay, false)
+	}
+
+	if g.showCollisionBoxes {
+		if g.tileMap != nil {
+			g.tileMap.DrawCollisionDebug(screen, cameraX, cameraY)
+		}
+
+		px, py, pw, ph := g.player.GetBounds()
+		screenPX, screenPY := camera.WorldToScreen(px, py)
+		vector.StrokeRect(screen, float32(screenPX), float32(screenPY), float32(pw), float32(ph), 1, color.RGBA{0, 255, 0, 255}, false)
+
+		if g.player.IsPerformingAttack() {
+			ax, ay, aw, ah := g.player.GetAttackBox()
+			screenAX, screenAY := camera.WorldToScreen(ax, ay)
+			vector.StrokeRect(screen, float32(screenAX), float32(screenAY), float32(aw), float32(ah), 2, color.RGBA{255, 0, 0, 200}, false)
+		}
+	}
+
+	g.drawHealthBar(screen)
+
+	if g.currentGlitchMessage != "" && g.messageTimer > 0 {
+		messageColor := color.RGBA{
+			uint8(255 * (0.5 + 0.5*math.Sin(g.realityGlitchTimer*20.0))),
+			uint8(100 * (0.5 + 0.5*math.Sin(g.realityGlitchTimer*15.0))),
+			uint8(100 * (0.5 + 0.5*math.Sin(g.realityGlitchTimer*25.0))),
+			255,
+		}
+
+		textX := 50.0 + g.rng.Render.Float64()*float64(screenWidth-400)
+		textY := 50.0 + g.rng.Render.Float64()*100.0
+
+		esset.DrawText(screen, g.currentGlitchMessage, textX, textY, assets.FontFaceM, messageColor)
+	}
+
+	if g.madnessLevel > 0 {
+		madnessText := fmt.Sprintf("MADNESS: %.0f%%", g.madnessLevel*100)
+
+		if g.madnessLevel >= 0.9 {
+			madnessText = "⚠️ CRITICAL MADNESS: " + fmt.Sprintf("%.0f%%", g.madnessLevel*100) + " - DEATH IMMINENT! ⚠️"
+		}
+
+		madnessColor := color.RGBA{
+			uint8(255 * g.madnessLevel),
+			uint8(255 * (1.0 - g.madnessLevel)),
+			0,
+			255,
+		}
+
+		if g.madnessLevel >= 0.9 {
+			flashIntensity := 0.1 + 0.1*math.Sin(g.realityGlitchTimer*0.5)
+			madnessColor = color.RGBA{
+				255,
+				uint8(100 * flashIntensity),
+				uint8(100 * flashIntensity),
+				100,
+			}
+		}
+
+		esset.DrawText(screen, madnessText, float64(screenWidth-350), 10, assets.FontFaceS, madnessColor)
+	}
+
+	fps := ebiten.ActualFPS()
+	tps := ebiten.ActualTPS()
+	fpsTpsText := fmt.Sprintf("FPS: %.0f  TPS: %.0f", fps, tps)
+	esset.DrawText(screen, fpsTpsText, 10, 10, assets.FontFaceS, color.RGBA{255, 255, 255, 255})
+}
+
+type PauseScene struct {
+	game *Game
+}
+
+func NewPauseScene(g *Game) *PauseScene {
+	return &PauseScene{game: g}
+}
+
+func (s *PauseScene) IsOverlay() bool { return true }
+
+func (s *PauseScene) OnEnter() {
+	s.game.menu.SetPauseState()
+}
+
+func (s *PauseScene) OnExit() {}
+
+func (s *PauseScene) Update(deltaTime float64, input InputState) error {
+	g := s.game
+	if err := g.menu.Update(deltaTime, input); err != nil {
+		return err
+	}
+
+	if g.menu.IsSaveRequested() {
+		if err := g.SaveGame(g.savePath); err != nil {
+			log.Printf("Failed to save: %v", err)
+		} else {
+			g.menu.SetContinueAvailable(true)
+			g.currentGlitchMessage = "PROGRESS SAVED"
+			g.messageTimer = 2.0
+		}
+	}
+
+	if g.menu.IsExitSelected() {
+		return ebiten.Termination
+	}
+
+	if g.menu.IsContinueRequested() || input.IsJustPressed(ActionPause) {
+		g.scenes.Pop(NoTransition)
+	}
+
+	return nil
+}
+
+func (s *PauseScene) Draw(screen *ebiten.Image) {
+	s.game.menu.Draw(screen)
+}
+
+type DeathScene struct {
+	game *Game
+}
+
+func NewDeathScene(g *Game) *DeathScene {
+	return &DeathScene{game: g}
+}
+
+func (s *DeathScene) IsOverlay() bool { return true }
+
+func (s *DeathScene) OnEnter() {
+	s.game.menu.SetRespawnState()
+}
+
+func (s *DeathScene) OnExit() {}
+
+func (s *DeathScene) Update(deltaTime float64, input InputState) error {
+	g := s.game
+	if err := g.menu.Update(deltaTime, input); err != nil {
+		return err
+	}
+
+	if g.menu.IsExitSelected() {
+		return ebiten.Termination
+	}
+
+	if g.menu.IsRestartRequested() {
+		g.scenes.Run(FadeTransition, func() {
+			g.respawn()
+			g.scenes.Pop(NoTransition)
+		})
+	}
+
+	return nil
+}
+
+func (s *DeathScene) Draw(screen *ebiten.Image) {
+	bounds := screen.Bounds()
+	vector.DrawFilledRect(screen, 0, 0, float32(bounds.Dx()), float32(bounds.Dy()),
+		color.RGBA{0, 0, 0, 120}, false)
+
+	s.game.menu.Draw(screen)
+}
+
+// EndingScene plays the ending animation over the level while the world
+// effects keep running, and wipes to the UnionWinScene when it finishes.
+type EndingScene struct {
+	game *Game
+}
+
+func NewEndingScene(g *Game) *EndingScene {
+	return &EndingScene{game: g}
+}
+
+func (s *EndingScene) IsOverlay() bool { return true }
+
+func (s *EndingScene) OnEnter() {}
+
+func (s *EndingScene) OnExit() {}
+
+func (s *EndingScene) Update(deltaTime float64, input InputState) error {
+	g := s.game
+
+	g.updateSchizophrenicEffects(deltaTime)
+	g.updateChaosAtmosphere(deltaTime)
+
+	g.globalParticleSystem.Update(deltaTime, g.madnessLevel)
+	g.madnessParticleSystem.Update(deltaTime, g.madnessLevel)
+
+	g.endingAnimation.Update(deltaTime)
+	if g.endingAnimation.Finished() {
+		g.scenes.Replace(NewUnionWinScene(g), WipeTransition)
+		return nil
+	}
+
+	if input.IsJustPressed(ActionPause) {
+		g.scenes.Push(NewPauseScene(g), NoTransition)
+	}
+
+	return nil
+}
+
+func (s *EndingScene) Draw(screen *ebiten.Image) {
+	s.game.endingAnimation.Draw(screen)
+}
+
+// UnionWinScene shows the results of a completed run over the level and
+// returns to the main menu.
+type UnionWinScene struct {
+	game *Game
+}
+
+func NewUnionWinScene(g *Game) *UnionWinScene {
+	return &UnionWinScene{game: g}
+}
+
+func (s *UnionWinScene) OnEnter() {}
+
+func (s *UnionWinScene) OnExit() {}
+
+func (s *UnionWinScene) Update(deltaTime float64, input InputState) error {
+	g := s.game
+	if input.IsJustPressed(ActionPause) || input.IsJustPressed(ActionMenuSelect) {
+		g.scenes.Run(FadeTransition, func() {
+			if g.CurrentLevel().ID != g.levels.First().ID {
+				if err := g.loadLevel(g.levels.First().ID); err != nil {
+					log.Printf("Failed to load level: %v", err)
+				}
+			}
+			g.restartGame()
+			g.scenes.Switch(NewMenuScene(g), NoTransition)
+		})
+	}
+	return nil
+}
+
+func (s *UnionWinScene) Draw(screen *ebiten.Image) {
+	g := s.game
+	screenWidth, screenHeight := screen.Bounds().Dx(), screen.Bounds().Dy()
+
+	camera := g.player.GetCamera()
+	cameraX, cameraY := camera.GetView()
+	g.drawWorld(screen, camera, cameraX, cameraY, cameraX, cameraY)
+
+	unionIntensity := 0.2 + 0.1*math.Sin(g.realityGlitchTimer*2.0)
+	unionOverlay := color.RGBA{255, 255, 200, uint8(50 * unionIntensity)}
+	vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), unionOverlay, false)
+
+	victoryTitle := "🌟 UNION ACHIEVED 🌟"
+	titleX := float64(screenWidth)/2 - 200
+	titleY := float64(screenHeight)/2 - 100
+	esset.DrawText(screen, victoryTitle, titleX, titleY, assets.FontFaceM, color.RGBA{255, 255, 255, 255})
+
+	victoryMessage := "Mind and Matter are One"
+	messageX := float64(screenWidth)/2 - 150
+	messageY := titleY + 50
+	esset.DrawText(screen, victoryMessage, messageX, messageY, assets.FontFaceM, color.RGBA{200, 255, 200, 255})
+
+	statsText := fmt.Sprintf("Chaos Cleansed: %d/%d Items", g.totalItemsCollected, len(g.specialItems))
+	statsX := float64(screenWidth)/2 - 120
+	statsY := messageY + 80
+	esset.DrawText(screen, statsText, statsX, statsY, assets.FontFaceS, color.RGBA{255, 255, 255, 200})
+
+	stabilityText := fmt.Sprintf("World Stability: %.0f%%", g.worldStabilityLevel*100)
+	stabilityX := float64(screenWidth)/2 - 100
+	stabilityY := statsY + 30
+	esset.DrawText(screen, stabilityText, stabilityX, stabilityY, assets.FontFaceS, color.RGBA{255, 255, 255, 200})
+
+	continueText := "Press ESCAPE, ENTER, or SPACE to continue"
+	continueX := float64(screenWidth)/2 - 200
+	continueY := stabilityY + 60
+	continueColor := color.RGBA{255, 255, 255, uint8(150 + 100*math.Sin(g.realityGlitchTimer*4.0))}
+	esset.DrawText(screen, continueText, continueX, continueY, assets.FontFaceS, continueColor)
+}
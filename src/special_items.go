@@ -3,10 +3,11 @@ package src
 import (
 	"image/color"
 	"math"
-	"math/rand"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/ebijam25/assets"
 )
 
 type SpecialItemType int
@@ -21,6 +22,7 @@ const (
 )
 
 type SpecialItem struct {
+	ObjectID          uint32
 	X, Y              float64
 	Width, Height     float64
 	ItemType          SpecialItemType
@@ -49,9 +51,55 @@ type SpecialItem struct {
 	MovementType  int
 	TeleportTimer float64
 	CanTeleport   bool
+
+	rng *RandomStreams
+}
+
+const UnionCrystalSpawnType = "union_crystal_spawn"
+
+var specialItemConstructors = map[string]func(x, y float64, rng *RandomStreams) *SpecialItem{
+	"schizophrenic_fragment": NewSchizophrenicFragment,
+	"reality_glitch":         NewRealityGlitch,
+	"madness_core":           NewMadnessCore,
+	"union_crystal":          NewUnionCrystal,
+}
+
+// LoadSpecialItems builds items from every object whose type names an item,
+// ignoring case like TileMap.ObjectsOfType. Objects may override the
+// defaults with the health, movement, teleport and radius properties.
+func LoadSpecialItems(objects []assets.MapObject, rng *RandomStreams) []*SpecialItem {
+	var items []*SpecialItem
+	for _, object := range objects {
+		constructor, ok := specialItemConstructors[strings.ToLower(object.Type)]
+		if !ok {
+			continue
+		}
+
+		item := constructor(object.X, object.Y, rng)
+		item.ObjectID = object.ID
+		item.applyObjectProperties(object)
+		items = append(items, item)
+	}
+	return items
 }
 
-func NewSchizophrenicFragment(x, y float64) *SpecialItem {
+func (si *SpecialItem) applyObjectProperties(object assets.MapObject) {
+	if object.HasProperty("health") {
+		si.MaxHealth = object.Properties.GetInt("health")
+		si.Health = si.MaxHealth
+	}
+	if object.HasProperty("movement") {
+		si.MovementType = object.Properties.GetInt("movement")
+	}
+	if object.HasProperty("teleport") {
+		si.CanTeleport = object.Properties.GetBool("teleport")
+	}
+	if object.HasProperty("radius") {
+		si.MadnessRadius = object.Properties.GetFloat("radius")
+	}
+}
+
+func NewSchizophrenicFragment(x, y float64, rng *RandomStreams) *SpecialItem {
 	return &SpecialItem{
 		X:                 x,
 		Y:                 y,
@@ -65,7 +113,7 @@ func NewSchizophrenicFragment(x, y float64) *SpecialItem {
 		GlowColor:         color.RGBA{255, 100, 255, 100},
 		Name:              "FRAGMENT OF MADNESS",
 		Description:       "A shard that breaks reality...",
-		ParticleSystem:    NewParticleSystem(10),
+		ParticleSystem:    NewParticleSystem(10, rng),
 		IntensityLevel:    0.4,
 		MadnessRadius:     60,
 		LastParticleSpawn: 0,
@@ -74,18 +122,20 @@ func NewSchizophrenicFragment(x, y float64) *SpecialItem {
 		HitFlashTimer:     0,
 		IsBeingHit:        false,
 
-		VelocityX:     (rand.Float64() - 0.5) * 20.0,
-		VelocityY:     (rand.Float64() - 0.5) * 20.0,
+		VelocityX:     (rng.Items.Float64() - 0.5) * 20.0,
+		VelocityY:     (rng.Items.Float64() - 0.5) * 20.0,
 		OriginalX:     x,
 		OriginalY:     y,
 		MovementTimer: 0,
-		MovementType:  rand.Intn(3),
-		TeleportTimer: rand.Float64() * 10.0,
+		MovementType:  rng.Items.Intn(3),
+		TeleportTimer: rng.Items.Float64() * 10.0,
 		CanTeleport:   true,
+
+		rng: rng,
 	}
 }
 
-func NewRealityGlitch(x, y float64) *SpecialItem {
+func NewRealityGlitch(x, y float64, rng *RandomStreams) *SpecialItem {
 	return &SpecialItem{
 		X:                 x,
 		Y:                 y,
@@ -99,7 +149,7 @@ func NewRealityGlitch(x, y float64) *SpecialItem {
 		GlowColor:         color.RGBA{255, 255, 100, 80},
 		Name:              "GLITCH IN THE MATRIX",
 		Description:       "ERROR 404: SANITY NOT FOUND",
-		ParticleSystem:    NewParticleSystem(15),
+		ParticleSystem:    NewParticleSystem(15, rng),
 		IntensityLevel:    0.6,
 		MadnessRadius:     80,
 		LastParticleSpawn: 0,
@@ -108,18 +158,20 @@ func NewRealityGlitch(x, y float64) *SpecialItem {
 		HitFlashTimer:     0,
 		IsBeingHit:        false,
 
-		VelocityX:     (rand.Float64() - 0.5) * 40.0,
-		VelocityY:     (rand.Float64() - 0.5) * 40.0,
+		VelocityX:     (rng.Items.Float64() - 0.5) * 40.0,
+		VelocityY:     (rng.Items.Float64() - 0.5) * 40.0,
 		OriginalX:     x,
 		OriginalY:     y,
 		MovementTimer: 0,
-		MovementType:  rand.Intn(4),
-		TeleportTimer: rand.Float64() * 5.0,
+		MovementType:  rng.Items.Intn(4),
+		TeleportTimer: rng.Items.Float64() * 5.0,
 		CanTeleport:   true,
+
+		rng: rng,
 	}
 }
 
-func NewMadnessCore(x, y float64) *SpecialItem {
+func NewMadnessCore(x, y float64, rng *RandomStreams) *SpecialItem {
 	return &SpecialItem{
 		X:                 x,
 		Y:                 y,
@@ -133,7 +185,7 @@ func NewMadnessCore(x, y float64) *SpecialItem {
 		GlowColor:         color.RGBA{255, 50, 50, 120},
 		Name:              "CORE OF INSANITY",
 		Description:       "THE VOICES ARE GETTING LOUDER...",
-		ParticleSystem:    NewParticleSystem(20),
+		ParticleSystem:    NewParticleSystem(20, rng),
 		IntensityLevel:    1.0,
 		MadnessRadius:     120,
 		LastParticleSpawn: 0,
@@ -142,18 +194,20 @@ func NewMadnessCore(x, y float64) *SpecialItem {
 		HitFlashTimer:     0,
 		IsBeingHit:        false,
 
-		VelocityX:     (rand.Float64() - 0.5) * 10.0,
-		VelocityY:     (rand.Float64() - 0.5) * 10.0,
+		VelocityX:     (rng.Items.Float64() - 0.5) * 10.0,
+		VelocityY:     (rng.Items.Float64() - 0.5) * 10.0,
 		OriginalX:     x,
 		OriginalY:     y,
 		MovementTimer: 0,
 		MovementType:  1,
-		TeleportTimer: rand.Float64() * 8.0,
+		TeleportTimer: rng.Items.Float64() * 8.0,
 		CanTeleport:   true,
+
+		rng: rng,
 	}
 }
 
-func NewUnionCrystal(x, y float64) *SpecialItem {
+func NewUnionCrystal(x, y float64, rng *RandomStreams) *SpecialItem {
 	return &SpecialItem{
 		X:                 x,
 		Y:                 y,
@@ -167,7 +221,7 @@ func NewUnionCrystal(x, y float64) *SpecialItem {
 		GlowColor:         color.RGBA{200, 200, 255, 160},
 		Name:              "CRYSTAL OF UNION",
 		Description:       "The final piece... Unity of mind and matter",
-		ParticleSystem:    NewParticleSystem(10),
+		ParticleSystem:    NewParticleSystem(10, rng),
 		IntensityLevel:    -1.0,
 		MadnessRadius:     70,
 		LastParticleSpawn: 0,
@@ -184,6 +238,8 @@ func NewUnionCrystal(x, y float64) *SpecialItem {
 		MovementType:  0,
 		TeleportTimer: 15.0,
 		CanTeleport:   true,
+
+		rng: rng,
 	}
 }
 
@@ -217,22 +273,22 @@ func (si *SpecialItem) Update(deltaTime float64) {
 		si.LastParticleSpawn = 0
 	}
 
-	if rand.Float64() < 0.02*si.IntensityLevel {
+	if si.rng.Effects.Float64() < 0.02*si.IntensityLevel {
 		switch si.ItemType {
 		case ItemSchizophrenicFragment:
-			si.Color.R = uint8(180 + rand.Intn(50))
-			si.Color.G = uint8(rand.Intn(50))
-			si.Color.B = uint8(220 + rand.Intn(35))
+			si.Color.R = uint8(180 + si.rng.Effects.Intn(50))
+			si.Color.G = uint8(si.rng.Effects.Intn(50))
+			si.Color.B = uint8(220 + si.rng.Effects.Intn(35))
 
 		case ItemRealityGlitch:
-			si.Color.R = uint8(220 + rand.Intn(35))
-			si.Color.G = uint8(220 + rand.Intn(35))
-			si.Color.B = uint8(rand.Intn(80))
+			si.Color.R = uint8(220 + si.rng.Effects.Intn(35))
+			si.Color.G = uint8(220 + si.rng.Effects.Intn(35))
+			si.Color.B = uint8(si.rng.Effects.Intn(80))
 
 		case ItemMadnessCore:
-			si.Color.R = uint8(220 + rand.Intn(35))
-			si.Color.G = uint8(rand.Intn(30))
-			si.Color.B = uint8(rand.Intn(30))
+			si.Color.R = uint8(220 + si.rng.Effects.Intn(35))
+			si.Color.G = uint8(si.rng.Effects.Intn(30))
+			si.Color.B = uint8(si.rng.Effects.Intn(30))
 		default:
 		}
 
@@ -255,8 +311,8 @@ func (si *SpecialItem) UpdateMovement(deltaTime float64) {
 
 	case 1:
 		if int(si.MovementTimer*10)%20 == 0 {
-			si.VelocityX = (rand.Float64()*2 - 1) * 80.0
-			si.VelocityY = (rand.Float64()*2 - 1) * 80.0
+			si.VelocityX = (si.rng.Items.Float64()*2 - 1) * 80.0
+			si.VelocityY = (si.rng.Items.Float64()*2 - 1) * 80.0
 		}
 
 	case 2:
@@ -268,8 +324,8 @@ func (si *SpecialItem) UpdateMovement(deltaTime float64) {
 
 	case 3:
 		if int(si.MovementTimer*20)%10 == 0 {
-			si.VelocityX = (rand.Float64() - 0.5) * 120.0
-			si.VelocityY = (rand.Float64() - 0.5) * 120.0
+			si.VelocityX = (si.rng.Items.Float64() - 0.5) * 120.0
+			si.VelocityY = (si.rng.Items.Float64() - 0.5) * 120.0
 		}
 	}
 
@@ -311,8 +367,8 @@ func (si *SpecialItem) UpdateMovement(deltaTime float64) {
 }
 
 func (si *SpecialItem) Teleport() {
-	offsetX := rand.Float64()*200 - 100
-	offsetY := rand.Float64()*200 - 100
+	offsetX := si.rng.Items.Float64()*200 - 100
+	offsetY := si.rng.Items.Float64()*200 - 100
 	si.X = si.OriginalX + offsetX
 	si.Y = si.OriginalY + offsetY
 
@@ -337,8 +393,8 @@ func (si *SpecialItem) spawnAuraParticles() {
 	count := int(si.IntensityLevel*3) + 1
 
 	for i := 0; i < count; i++ {
-		angle := rand.Float64() * math.Pi * 2
-		distance := 20 + rand.Float64()*si.MadnessRadius
+		angle := si.rng.Particles.Float64() * math.Pi * 2
+		distance := 20 + si.rng.Particles.Float64()*si.MadnessRadius
 
 		particleX := si.X + si.Width/2 + math.Cos(angle)*distance
 		particleY := si.Y + si.Height/2 + math.Sin(angle)*distance
@@ -369,7 +425,7 @@ func (si *SpecialItem) spawnAuraParticles() {
 				ParticleTypeEnergyBeam,
 			}
 
-			selectedType := particleTypes[rand.Intn(len(particleTypes))]
+			selectedType := particleTypes[si.rng.Particles.Intn(len(particleTypes))]
 
 			if selectedType == ParticleTypeChaosOrb {
 				si.ParticleSystem.SpawnParticle(particleX, particleY, selectedType)
@@ -411,7 +467,7 @@ func (si *SpecialItem) spawnAuraParticles() {
 				ParticleTypeStabilityWave,
 			}
 
-			selectedType := unionTypes[rand.Intn(len(unionTypes))]
+			selectedType := unionTypes[si.rng.Particles.Intn(len(unionTypes))]
 			si.ParticleSystem.SpawnParticle(particleX, particleY, selectedType)
 
 			if selectedType == ParticleTypeUnionBeam && len(si.ParticleSystem.Particles) > 0 {
@@ -474,11 +530,11 @@ func (si *SpecialItem) Draw(screen *ebiten.Image, cameraX, cameraY float64) {
 
 	case ItemRealityGlitch:
 		for i := 0; i < 5; i++ {
-			glitchOffset := (rand.Float64() - 0.5) * 8
-			glitchSize := itemSize * (0.7 + 0.3*rand.Float64())
+			glitchOffset := (si.rng.Render.Float64() - 0.5) * 8
+			glitchSize := itemSize * (0.7 + 0.3*si.rng.Render.Float64())
 
 			glitchColor := flashedColor
-			if rand.Float64() < 0.1 {
+			if si.rng.Render.Float64() < 0.1 {
 				glitchColor = color.RGBA{220, 220, 220, flashedColor.A}
 			}
 
@@ -565,8 +621,8 @@ func (si *SpecialItem) Draw(screen *ebiten.Image, cameraX, cameraY float64) {
 
 	if si.IntensityLevel > 0.7 && math.Sin(si.AuraTimer*4) > 0.8 {
 		for i := 0; i < 5; i++ {
-			tearAngle := rand.Float64() * math.Pi * 2
-			tearDistance := rand.Float64() * si.MadnessRadius
+			tearAngle := si.rng.Render.Float64() * math.Pi * 2
+			tearDistance := si.rng.Render.Float64() * si.MadnessRadius
 			tearX := screenX + math.Cos(tearAngle)*tearDistance
 			tearY := screenY + math.Sin(tearAngle)*tearDistance
 
@@ -644,7 +700,7 @@ func (si *SpecialItem) TakeHit() bool {
 
 	if si.CanTeleport && si.Health > 0 {
 		teleportChance := 1.0 - (float64(si.Health)/float64(si.MaxHealth))*0.5
-		if rand.Float64() < teleportChance {
+		if si.rng.Items.Float64() < teleportChance {
 			si.Teleport()
 			si.TeleportTimer = 0
 		}